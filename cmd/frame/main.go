@@ -0,0 +1,104 @@
+// Command frame builds frames from markdown, slide and asset directories and
+// either serves them or exports them as a static site.
+//
+//	frame serve  -landing "Hello" -readme README.md -slides ./slides -path ./img
+//	frame export -out dist -readme README.md -path ./img
+//	frame serve  -docs ./handbook -watch
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/timefactoryio/frame"
+)
+
+// list collects a repeatable flag.
+type list []string
+
+func (l *list) String() string     { return strings.Join(*l, ",") }
+func (l *list) Set(v string) error { *l = append(*l, v); return nil }
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] != "serve" && os.Args[1] != "export") {
+		fmt.Fprintln(os.Stderr, "usage: frame serve|export [flags]")
+		os.Exit(2)
+	}
+	cmd := os.Args[1]
+
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	var readmes, slides, paths, docs list
+	fs.Var(&readmes, "readme", "markdown file to render as a frame (repeatable)")
+	fs.Var(&slides, "slides", "directory of slide images (repeatable)")
+	fs.Var(&paths, "path", "directory of assets to serve (repeatable)")
+	fs.Var(&docs, "docs", "directory of markdown pages to render as a documentation site (repeatable)")
+	landing := fs.String("landing", "", "heading of a landing frame")
+	github := fs.String("github", "", "GitHub username linked from the landing frame")
+	x := fs.String("x", "", "X username linked from the landing frame")
+	pathless := fs.String("pathless", "", "host of the pathless client")
+	api := fs.String("api", "", "public URL of this server")
+	addr := fs.String("addr", ":1001", "address to listen on")
+	watch := fs.Bool("watch", false, "reload sources when they change")
+	mathml := fs.Bool("mathml", false, "render markdown math as MathML on the server instead of for MathJax")
+	highlight := fs.String("highlight", "", "chroma style to highlight code with on the server, e.g. github")
+	out := fs.String("out", "dist", "export directory")
+	fs.Parse(os.Args[2:])
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	opts := []frame.Option{
+		frame.WithPathlessURL(*pathless),
+		frame.WithAPIURL(*api),
+		frame.WithAddr(*addr),
+		frame.WithLogger(logger),
+	}
+	if *mathml {
+		opts = append(opts, frame.WithMath(frame.MathML))
+	}
+	if *highlight != "" {
+		opts = append(opts, frame.WithHighlighting(*highlight))
+	}
+	f := frame.New(opts...)
+	for _, dir := range paths {
+		if _, err := f.AddPath(dir); err != nil {
+			logger.Warn("adding path", "dir", dir, "err", err)
+		}
+	}
+	if *landing != "" {
+		f.Landing(*landing, *github, *x)
+	}
+	for _, file := range readmes {
+		f.README(file)
+	}
+	for _, dir := range docs {
+		f.Docs(dir)
+	}
+	for _, dir := range slides {
+		f.BuildSlides(dir)
+	}
+
+	if cmd == "export" {
+		if err := f.Export(*out); err != nil {
+			logger.Error("export failed", "err", err)
+			os.Exit(1)
+		}
+		logger.Info("exported", "dir", *out, "frames", f.Count())
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *watch {
+		go f.Watch(ctx, time.Second)
+	}
+	logger.Info("serving", "addr", *addr, "frames", f.Count())
+	if err := f.Serve(ctx); err != nil {
+		logger.Error("serve failed", "err", err)
+		os.Exit(1)
+	}
+}
@@ -14,10 +14,43 @@ type Frame struct {
 	zero.Zero
 }
 
-func NewFrame(pathlessUrl, apiURL string) *Frame {
+// Option configures a Frame built with New.
+type Option = zero.Option
+
+// Math rendering modes for WithMath.
+const (
+	MathJax = zero.MathJax
+	MathML  = zero.MathML
+)
+
+var (
+	WithPathlessURL     = zero.WithPathlessURL
+	WithAPIURL          = zero.WithAPIURL
+	WithAddr            = zero.WithAddr
+	WithOrigins         = zero.WithOrigins
+	WithCORSHeaders     = zero.WithCORSHeaders
+	WithCORSMethods     = zero.WithCORSMethods
+	WithMarkdown        = zero.WithMarkdown
+	WithLogger          = zero.WithLogger
+	WithCompression     = zero.WithCompression
+	WithEncodings       = zero.WithEncodings
+	WithCacheControl    = zero.WithCacheControl
+	WithDiskThreshold   = zero.WithDiskThreshold
+	WithCacheSize       = zero.WithCacheSize
+	WithCollisionPolicy = zero.WithCollisionPolicy
+	WithHighlighting    = zero.WithHighlighting
+	WithLineNumbers     = zero.WithLineNumbers
+	WithMath            = zero.WithMath
+)
+
+func New(opts ...Option) *Frame {
 	f := &Frame{
-		Zero: zero.NewZero(pathlessUrl, apiURL),
+		Zero: zero.New(opts...),
 	}
 	f.Templates = templates.NewTemplates(f.Zero)
 	return f
 }
+
+func NewFrame(pathlessUrl, apiURL string) *Frame {
+	return New(WithPathlessURL(pathlessUrl), WithAPIURL(apiURL))
+}
@@ -0,0 +1,268 @@
+package templates
+
+import (
+	"cmp"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/timefactoryio/frame/zero"
+	"github.com/yuin/goldmark/ast"
+)
+
+// Docs turns a directory of markdown files into a documentation site: a
+// navigation frame named after dir listing every page, and one frame per page
+// named <dir>-<path>, unless front matter names it. Relative links between pages
+// navigate between their frames, and the other files in dir, such as images, are
+// served through AddPath; the markdown sources are not. In watch mode pages are
+// re-rendered, added and removed as dir changes, after AddPath has picked up new
+// and removed files.
+func (t *templates) Docs(dir string) *zero.One {
+	if _, err := t.AddPath(dir, zero.SkipExt(".md")); err != nil {
+		t.Logger().Warn("adding docs assets", "dir", dir, "err", err)
+	}
+	d := t.newDocs(os.DirFS(dir), filepath.Base(dir), func(name string) string {
+		source := filepath.Join(dir, filepath.FromSlash(name))
+		for _, r := range t.Routes() {
+			if r.Source == source {
+				return r.Path
+			}
+		}
+		return ""
+	})
+	nav := d.build()
+	t.Track(dir, func() { d.build() })
+	return nav
+}
+
+// DocsFS builds a documentation site from the markdown files in fsys, serving its
+// other files under /<prefix>/ through AddFS.
+func (t *templates) DocsFS(fsys fs.FS, prefix string) *zero.One {
+	routes, err := t.AddFS(fsys, prefix, zero.SkipExt(".md"))
+	if err != nil {
+		t.Logger().Warn("adding docs assets", "prefix", prefix, "err", err)
+	}
+	d := t.newDocs(fsys, strings.Trim(prefix, "/"), func(name string) string {
+		route := "/" + strings.Trim(prefix, "/") + "/" + strings.TrimSuffix(name, path.Ext(name))
+		if slices.Contains(routes, route) {
+			return route
+		}
+		return ""
+	})
+	return d.build()
+}
+
+// docs is a documentation site and the frames it has registered.
+type docs struct {
+	t     *templates
+	fsys  fs.FS
+	title string
+	name  string
+	// asset returns the route serving a file in fsys, or "".
+	asset func(name string) string
+	nav   string
+	keys  map[string]string
+}
+
+func (t *templates) newDocs(fsys fs.FS, title string, asset func(string) string) *docs {
+	return &docs{t: t, fsys: fsys, title: title, name: frameName(title), asset: asset, keys: make(map[string]string)}
+}
+
+// build renders every page and the navigation frame, registering frames for new
+// pages and removing those of deleted pages and drafts. Frames are registered
+// before rendering so links can point at their final keys.
+func (d *docs) build() *zero.One {
+	t := d.t
+	empty := zero.One("")
+	if d.nav == "" {
+		d.nav = t.index(d.name, &empty)
+	}
+
+	var pages []*page
+	for _, file := range d.files() {
+		p, err := t.parse(d.fsys, file, d.image(file))
+		if err != nil {
+			t.Logger().Warn("reading markdown", "file", file, "err", err)
+			continue
+		}
+		if !p.fm.Draft {
+			pages = append(pages, p)
+		}
+	}
+
+	live := make(map[string]bool, len(pages))
+	for _, p := range pages {
+		live[p.file] = true
+		if _, ok := d.keys[p.file]; !ok {
+			d.keys[p.file] = t.index(cmp.Or(p.fm.Name, d.pageName(p.file)), &empty)
+		}
+	}
+	for file, key := range d.keys {
+		if !live[file] {
+			t.RemoveFrame(key)
+			delete(d.keys, file)
+		}
+	}
+
+	for _, p := range pages {
+		d.rewrite(p)
+		doc := t.render(p)
+		key := d.keys[p.file]
+		t.ReplaceFrame(key, doc.frame)
+		t.SetMeta(key, doc.frameMeta())
+		t.place(key, doc.meta.Weight)
+	}
+
+	nav := d.navigation(pages)
+	t.ReplaceFrame(d.nav, nav)
+	return nav
+}
+
+// files lists the markdown files in fsys in navigation order, skipping dotfiles.
+func (d *docs) files() []string {
+	var files []string
+	fs.WalkDir(d.fsys, ".", func(name string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if strings.HasPrefix(e.Name(), ".") && name != "." {
+			if e.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !e.IsDir() && strings.EqualFold(path.Ext(name), ".md") {
+			files = append(files, name)
+		}
+		return nil
+	})
+	slices.SortFunc(files, pageOrder)
+	return files
+}
+
+// pageOrder sorts a directory's pages before its subdirectories, each in natural order.
+func pageOrder(a, b string) int {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		aFile, bFile := i == len(as)-1, i == len(bs)-1
+		switch {
+		case aFile && !bFile:
+			return -1
+		case bFile && !aFile:
+			return 1
+		}
+		if c := naturalCompare(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return len(as) - len(bs)
+}
+
+// pageName is the default frame name of a page: the site name and the page's
+// path without extension, joined by dashes.
+func (d *docs) pageName(file string) string {
+	rel := strings.TrimSuffix(file, path.Ext(file))
+	return d.name + "-" + strings.ToLower(strings.ReplaceAll(rel, "/", "-"))
+}
+
+// rewrite points relative links to other pages at their frames. Links to pages
+// without one, such as drafts, are unwrapped to their text since the markdown
+// is not served. Images were resolved to their routes while parsing.
+func (d *docs) rewrite(p *page) {
+	var dead []*ast.Link
+	ast.Walk(p.doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		link, ok := n.(*ast.Link)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		target, ok := relative(p.file, string(link.Destination))
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		if key, ok := d.keys[target]; ok {
+			link.Destination = []byte("#" + key)
+		} else if strings.EqualFold(path.Ext(target), ".md") {
+			dead = append(dead, link)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, link := range dead {
+		parent := link.Parent()
+		for c := link.FirstChild(); c != nil; c = link.FirstChild() {
+			parent.InsertBefore(parent, link, c)
+		}
+		parent.RemoveChild(parent, link)
+	}
+}
+
+// image resolves the relative image references in file to their asset routes.
+func (d *docs) image(file string) func(string) string {
+	return func(dest string) string {
+		if target, ok := relative(file, dest); ok {
+			return d.asset(target)
+		}
+		return ""
+	}
+}
+
+// relative resolves a relative reference in file to a path in the site,
+// dropping any query or fragment.
+func relative(file, ref string) (string, bool) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return "", false
+	}
+	target := path.Join(path.Dir(file), u.Path)
+	if target == ".." || strings.HasPrefix(target, "../") {
+		return "", false
+	}
+	return target, true
+}
+
+// navigation builds the table of contents: pages in directory order, with
+// subdirectories as nested lists.
+func (d *docs) navigation(pages []*page) *zero.One {
+	t := d.t
+	root := &navDir{}
+	for _, p := range pages {
+		dir := root
+		parts := strings.Split(p.file, "/")
+		for _, part := range parts[:len(parts)-1] {
+			dir = dir.child(part)
+		}
+		dir.items = append(dir.items, t.Link("#"+d.keys[p.file], p.title()))
+	}
+	css := t.CSS(t.TextCSS())
+	return t.Build("text docs", false, t.H1(d.title), t.List(root.list(), false), &css)
+}
+
+// navDir is a directory in the table of contents.
+type navDir struct {
+	name  string
+	items []any
+	dirs  []*navDir
+}
+
+func (n *navDir) child(name string) *navDir {
+	for _, c := range n.dirs {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &navDir{name: name}
+	n.dirs = append(n.dirs, c)
+	return c
+}
+
+// list renders pages first, then each subdirectory's name followed by its own list.
+func (n *navDir) list() []any {
+	items := slices.Clone(n.items)
+	for _, c := range n.dirs {
+		items = append(items, c.name, c.list())
+	}
+	return items
+}
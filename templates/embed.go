@@ -1,6 +1,10 @@
 package templates
 
-import _ "embed"
+import (
+	_ "embed"
+
+	"github.com/timefactoryio/frame/zero"
+)
 
 //go:embed css/zero.css
 var zeroCSS string
@@ -23,12 +27,20 @@ type Style interface {
 	FooterCSS() string
 	TextCSS() string
 	KeyboardCSS() string
+	CodeCSS() string
 }
 
-type style struct{}
+type style struct {
+	// highlight is the chroma style CodeCSS is generated from.
+	highlight string
+}
 
 func NewStyle() Style {
-	return &style{}
+	return newStyle(zero.DefaultHighlightStyle)
+}
+
+func newStyle(highlight string) *style {
+	return &style{highlight: highlight}
 }
 
 func (s *style) ZeroCSS() string {
@@ -50,3 +62,11 @@ func (s *style) TextCSS() string {
 func (s *style) KeyboardCSS() string {
 	return keyboardCSS
 }
+
+// CodeCSS styles server-highlighted code; it is empty when highlighting is off.
+func (s *style) CodeCSS() string {
+	if s.highlight == "" {
+		return ""
+	}
+	return zero.HighlightCSS(s.highlight)
+}
@@ -1,15 +1,23 @@
 package templates
 
-import "github.com/timefactoryio/frame/zero"
+import (
+	"io/fs"
+
+	"github.com/timefactoryio/frame/zero"
+)
 
 type Templates interface {
 	Style
-	GithubLink(username string) *zero.One
-	XLink(username string) *zero.One
+	GithubLink(username string) *zero.Node
+	XLink(username string) *zero.Node
 	Landing(heading, github, x string)
 	README(file string) *zero.One
+	READMEFS(fsys fs.FS, name string) *zero.One
 	Scroll() *zero.One
 	BuildSlides(dir string) *zero.One
+	BuildSlidesFS(fsys fs.FS, prefix string) *zero.One
+	Docs(dir string) *zero.One
+	DocsFS(fsys fs.FS, prefix string) *zero.One
 }
 
 type templates struct {
@@ -19,7 +27,7 @@ type templates struct {
 
 func NewTemplates(zero zero.Zero) Templates {
 	return &templates{
-		Style: NewStyle(),
+		Style: newStyle(zero.HighlightStyle()),
 		Zero:  zero,
 	}
 }
@@ -2,12 +2,25 @@ package templates
 
 import (
 	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/timefactoryio/frame/zero"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"go.abhg.dev/goldmark/frontmatter"
 )
 
 func (t *templates) Landing(heading, github, x string) {
@@ -16,7 +29,58 @@ func (t *templates) Landing(heading, github, x string) {
 	h1 := t.H1(heading)
 	css := t.CSS(t.ZeroCSS())
 	footer := t.buildFooter(github, x)
-	t.Build("zero", true, &css, img, h1, footer)
+	t.index("landing", t.Build("zero", false, &css, img, h1, footer))
+}
+
+// index registers frame under name, suffixing -2, -3, ... if the name is taken, and
+// returns the key it was registered under. Names the index rejects are made valid
+// first, so every frame has a name that survives moves and removals. Renames are
+// logged, and clients find the final names in the index and manifest. The frame
+// is placed with weight 0. A frame the index still rejects is logged and not
+// indexed, returning "".
+func (t *templates) index(name string, frame *zero.One) string {
+	name = validName(name)
+	candidate := name
+	for n := 2; ; n++ {
+		err := t.Register(candidate, frame)
+		if err == nil {
+			if candidate != name {
+				t.Logger().Info("frame name taken", "name", name, "registered", candidate)
+			}
+			t.place(candidate, 0)
+			return candidate
+		}
+		if !errors.Is(err, zero.ErrFrameExists) {
+			t.Logger().Warn("indexing frame", "name", candidate, "err", err)
+			return ""
+		}
+		candidate = name + "-" + strconv.Itoa(n)
+	}
+}
+
+// validName turns name into one the index accepts: reserved characters become
+// dashes, and empty or numeric names, which would read as positions, are
+// prefixed with "frame".
+func validName(name string) string {
+	name = strings.TrimFunc(strings.Map(func(r rune) rune {
+		if strings.ContainsRune("/?#", r) {
+			return '-'
+		}
+		return r
+	}, strings.ToLower(name)), func(r rune) bool { return r == '-' || unicode.IsSpace(r) })
+	if name == "" {
+		return "frame"
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return "frame-" + name
+	}
+	return name
+}
+
+// frameName derives a frame name from a file or directory path.
+func frameName(path string) string {
+	base := filepath.Base(path)
+	return strings.ToLower(strings.TrimSuffix(base, filepath.Ext(base)))
 }
 
 func (t *templates) buildFooter(github, x string) *zero.One {
@@ -25,7 +89,7 @@ func (t *templates) buildFooter(github, x string) *zero.One {
 	}
 
 	footerCSS := t.CSS(t.FooterCSS())
-	elements := []*zero.One{&footerCSS}
+	elements := []zero.Renderable{&footerCSS}
 
 	if github != "" {
 		elements = append(elements, t.GithubLink(github))
@@ -36,7 +100,7 @@ func (t *templates) buildFooter(github, x string) *zero.One {
 	return t.Build("footer", false, elements...)
 }
 
-func (t *templates) GithubLink(username string) *zero.One {
+func (t *templates) GithubLink(username string) *zero.Node {
 	if username == "" {
 		return nil
 	}
@@ -45,7 +109,7 @@ func (t *templates) GithubLink(username string) *zero.One {
 	return t.LinkedIcon(href, logo, "GitHub")
 }
 
-func (t *templates) XLink(username string) *zero.One {
+func (t *templates) XLink(username string) *zero.Node {
 	if username == "" {
 		return nil
 	}
@@ -54,32 +118,202 @@ func (t *templates) XLink(username string) *zero.One {
 	return t.LinkedIcon(href, logo, "X")
 }
 
+// README renders a markdown file as a text frame named after the file, with a -2,
+// -3, ... suffix when that name is taken. Front matter may set the frame's name
+// and metadata; drafts are rendered but not indexed. In watch mode the frame
+// is re-rendered in place whenever the file changes.
 func (t *templates) README(file string) *zero.One {
-	content, err := os.ReadFile(file)
+	fsys, name := os.DirFS(filepath.Dir(file)), filepath.Base(file)
+	doc := t.readme(fsys, name)
+	key := t.publish(frameName(file), doc)
+	t.Track(file, func() {
+		doc := t.readme(fsys, name)
+		switch {
+		case key == "":
+			key = t.publish(frameName(file), doc)
+		case doc.meta.Draft:
+			t.RemoveFrame(key)
+			key = ""
+		default:
+			t.ReplaceFrame(key, doc.frame)
+			t.SetMeta(key, doc.frameMeta())
+			t.place(key, doc.meta.Weight)
+		}
+	})
+	return doc.frame
+}
+
+// READMEFS renders the markdown file name in fsys as a text frame.
+func (t *templates) READMEFS(fsys fs.FS, name string) *zero.One {
+	doc := t.readme(fsys, name)
+	t.publish(frameName(name), doc)
+	return doc.frame
+}
+
+// frontMatter is the YAML (---) or TOML (+++) block a markdown file may start with.
+type frontMatter struct {
+	Name      string `yaml:"name" toml:"name"`
+	zero.Meta `yaml:",inline"`
+}
+
+// document is a rendered markdown file.
+type document struct {
+	frame   *zero.One
+	name    string
+	meta    zero.Meta
+	hasMeta bool
+}
+
+// frameMeta is the metadata to attach to the document's frame, nil when the
+// file has no front matter.
+func (d document) frameMeta() *zero.Meta {
+	if !d.hasMeta {
+		return nil
+	}
+	return &d.meta
+}
+
+// publish indexes doc under its front matter name, or fallback, and orders it by
+// weight. It returns the frame's key, or "" for drafts.
+func (t *templates) publish(fallback string, doc document) string {
+	if doc.meta.Draft {
+		t.Logger().Info("skipping draft", "frame", cmp.Or(doc.name, fallback))
+		return ""
+	}
+	key := t.index(cmp.Or(doc.name, fallback), doc.frame)
+	t.SetMeta(key, doc.frameMeta())
+	t.place(key, doc.meta.Weight)
+	return key
+}
+
+// place moves a frame to where a stable insertion by weight puts it: before the
+// first other frame with a higher weight, else last. Frames without metadata
+// weigh 0, so frames placed in weight order stay sorted however they are added.
+func (t *templates) place(key string, weight int) {
+	self, ok := t.Resolve(key)
+	if !ok {
+		return
+	}
+	pos := 0
+	for _, info := range t.Index() {
+		if info.Index == self {
+			continue
+		}
+		other := 0
+		if info.Meta != nil {
+			other = info.Meta.Weight
+		}
+		if other > weight {
+			break
+		}
+		pos++
+	}
+	if pos != self {
+		t.MoveFrame(key, pos)
+	}
+}
+
+func (t *templates) readme(fsys fs.FS, file string) document {
+	p, err := t.parse(fsys, file, nil)
 	if err != nil {
+		t.Logger().Warn("reading markdown", "file", file, "err", err)
 		empty := zero.One("")
-		return &empty
+		return document{frame: &empty}
 	}
+	return t.render(p)
+}
+
+// page is a markdown file parsed but not yet rendered, so its AST can be rewritten.
+type page struct {
+	file    string
+	source  []byte
+	doc     ast.Node
+	fm      frontMatter
+	hasMeta bool
+}
 
+// parse reads a markdown file. resolve, when set, maps the file's image
+// destinations to asset routes.
+func (t *templates) parse(fsys fs.FS, file string, resolve func(dest string) string) (*page, error) {
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	ctx := parser.NewContext()
+	zero.ParseFrontMatter(ctx)
+	if resolve != nil {
+		zero.ResolveImages(ctx, resolve)
+	}
+	p := &page{file: file, source: content}
+	p.doc = (*t.Markdown()).Parser().Parse(text.NewReader(content), parser.WithContext(ctx))
+	if data := frontmatter.Get(ctx); data != nil {
+		p.hasMeta = true
+		if err := data.Decode(&p.fm); err != nil {
+			t.Logger().Warn("reading front matter", "file", file, "err", err)
+		}
+	}
+	return p, nil
+}
+
+// title is the page's front matter title, else its first heading, else its file name.
+func (p *page) title() string {
+	if p.fm.Title != "" {
+		return p.fm.Title
+	}
+	for n := p.doc.FirstChild(); n != nil; n = n.NextSibling() {
+		if _, ok := n.(*ast.Heading); ok {
+			var b strings.Builder
+			ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+				if t, ok := n.(*ast.Text); ok && entering {
+					b.Write(t.Segment.Value(p.source))
+				}
+				return ast.WalkContinue, nil
+			})
+			return b.String()
+		}
+	}
+	return strings.TrimSuffix(path.Base(p.file), path.Ext(p.file))
+}
+
+func (t *templates) render(p *page) document {
 	var buf bytes.Buffer
-	if err := (*t.Markdown()).Convert(content, &buf); err != nil {
+	if err := (*t.Markdown()).Renderer().Render(&buf, p.source, p.doc); err != nil {
+		t.Logger().Warn("rendering markdown", "file", p.file, "err", err)
 		empty := zero.One("")
-		return &empty
+		return document{frame: &empty}
 	}
+	fm := p.fm
 
 	html := buf.String()
-	html = strings.ReplaceAll(html, "<p><img", "<img")
-	html = strings.ReplaceAll(html, "\"></p>", "\">")
-	html = strings.ReplaceAll(html, "\" /></p>", "\" />")
-	html = strings.ReplaceAll(html, "\"/></p>", "\"/>")
-
 	markdown := zero.One(template.HTML(html))
 	scroll := t.Scroll()
 
 	css := t.CSS(t.TextCSS())
+	var code zero.One
+	if strings.Contains(html, `class="chroma"`) {
+		code = t.CSS(t.CodeCSS())
+	}
+	elements := []zero.Renderable{&markdown, scroll, &css, &code}
+	for _, href := range fm.CSS {
+		elements = append(elements, zero.El("link").Attr("rel", "stylesheet").Attr("href", t.resolve(href)))
+	}
+	for _, src := range fm.JS {
+		elements = append(elements, zero.El("script").Attr("src", t.resolve(src)))
+	}
+	if p.hasMeta {
+		elements = append(elements, &fm.Meta)
+	}
+
+	class := strings.TrimSpace("text " + fm.Class)
+	return document{frame: t.Build(class, false, elements...), name: fm.Name, meta: fm.Meta, hasMeta: p.hasMeta}
+}
 
-	result := t.Build("text", true, &markdown, scroll, &css)
-	return result
+// resolve makes a root-relative asset path absolute against the API URL.
+func (t *templates) resolve(ref string) string {
+	if strings.HasPrefix(ref, "/") && !strings.HasPrefix(ref, "//") {
+		return t.ApiUrl() + ref
+	}
+	return ref
 }
 
 func (t *templates) Scroll() *zero.One {
@@ -127,7 +361,83 @@ func (t *templates) Scroll() *zero.One {
 }
 
 func (t *templates) BuildSlides(dir string) *zero.One {
-	prefix := t.AddPath(dir)
+	if _, err := t.AddPath(dir); err != nil {
+		t.Logger().Warn("adding slides", "dir", dir, "err", err)
+	}
+	prefix := filepath.Base(dir)
+	t.slideOrder(prefix)
+	t.Track(dir, func() { t.slideOrder(prefix) })
+	return t.slides(prefix)
+}
+
+// BuildSlidesFS serves the slides in fsys under prefix and builds a slides frame for them.
+func (t *templates) BuildSlidesFS(fsys fs.FS, prefix string) *zero.One {
+	if _, err := t.AddFS(fsys, prefix); err != nil {
+		t.Logger().Warn("adding slides", "prefix", prefix, "err", err)
+	}
+	prefix = strings.Trim(prefix, "/")
+	t.slideOrder(prefix)
+	return t.slides(prefix)
+}
+
+// slideOrder serves /<prefix>/order as a JSON list of the images under prefix in
+// natural order (2 before 10), unless the slides provide their own order file,
+// named order or order.json. An image named order is shadowed by the list.
+func (t *templates) slideOrder(prefix string) {
+	base := "/" + prefix + "/"
+	var slides []string
+	for _, route := range t.Routes() {
+		if route.Path == base+"order" && route.Source != "" {
+			if name := filepath.Base(route.Source); name == "order" || name == "order.json" {
+				return
+			}
+			t.Logger().Warn("slide shadowed by the generated order", "file", route.Source)
+			continue
+		}
+		if strings.HasPrefix(route.Path, base) && strings.HasPrefix(route.ContentType, "image/") {
+			slides = append(slides, strings.TrimPrefix(route.Path, base))
+		}
+	}
+	slices.SortFunc(slides, naturalCompare)
+	data, _ := json.Marshal(slides)
+	t.AddContent(base+"order", "application/json", data)
+}
+
+// naturalCompare orders strings with embedded numbers by numeric value, so
+// "slide2" sorts before "slide10".
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		da, db := digits(a), digits(b)
+		if da > 0 && db > 0 {
+			na := strings.TrimLeft(a[:da], "0")
+			nb := strings.TrimLeft(b[:db], "0")
+			if c := cmp.Compare(len(na), len(nb)); c != 0 {
+				return c
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			a, b = a[da:], b[db:]
+			continue
+		}
+		if a[0] != b[0] {
+			return cmp.Compare(a[0], b[0])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// digits returns the length of the run of ASCII digits at the start of s.
+func digits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+func (t *templates) slides(prefix string) *zero.One {
 	img := t.Img("", "")
 	css := t.CSS(t.SlidesCSS())
 	js := t.JS(fmt.Sprintf(`
@@ -170,5 +480,7 @@ func (t *templates) BuildSlides(dir string) *zero.One {
 })();
     `, prefix, prefix, prefix, prefix))
 
-	return t.Build("slides", true, img, &css, &js)
+	slides := t.Build("slides", false, img, &css, &js)
+	t.index(frameName(prefix), slides)
+	return slides
 }
@@ -0,0 +1,374 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/timefactoryio/frame/zero"
+)
+
+func TestNaturalCompare(t *testing.T) {
+	got := []string{"slide10", "slide2", "intro", "slide1", "slide02b", "slide02a"}
+	slices.SortFunc(got, naturalCompare)
+	want := []string{"intro", "slide1", "slide2", "slide02a", "slide02b", "slide10"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestIndexDuplicateNames(t *testing.T) {
+	tm := NewTemplates(zero.New()).(*templates)
+	empty := zero.One("")
+	got := []string{tm.index("Notes", &empty), tm.index("notes", &empty), tm.index("notes", &empty), tm.index("2024", &empty), tm.index("a/b", &empty), tm.index("- -", &empty), tm.index(" -\t- ", &empty)}
+	want := []string{"notes", "notes-2", "notes-3", "frame-2024", "a-b", "frame", "frame-2"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("keys = %v, want %v", got, want)
+	}
+	for i, key := range want {
+		if n, ok := tm.Resolve(key); !ok || n != i {
+			t.Errorf("Resolve(%q) = %d, %v", key, n, ok)
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "- -.md")
+	os.WriteFile(file, []byte("---\nname: \"- -\"\n---\n\nx\n"), 0o644)
+	done := make(chan struct{})
+	go func() {
+		tm.README(file)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("README with a dash-only name did not return")
+	}
+	if _, ok := tm.Resolve("frame-3"); !ok {
+		t.Errorf("dash-only README not indexed: %v", tm.Index())
+	}
+}
+
+func TestSlideOrderFallback(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	tm := NewTemplates(zero.New())
+	tm.BuildSlidesFS(fstest.MapFS{
+		"10.png":    {Data: png},
+		"2.png":     {Data: png},
+		"notes.txt": {Data: []byte("notes")},
+	}, "deck")
+
+	w := httptest.NewRecorder()
+	tm.(*templates).Router().ServeHTTP(w, httptest.NewRequest("GET", "/deck/order", nil))
+	if got := w.Body.String(); got != `["2","10"]` {
+		t.Errorf("generated order = %s", got)
+	}
+
+	tm.BuildSlidesFS(fstest.MapFS{
+		"a.png": {Data: png},
+		"order": {Data: []byte(`["a"]`)},
+	}, "custom")
+	w = httptest.NewRecorder()
+	tm.(*templates).Router().ServeHTTP(w, httptest.NewRequest("GET", "/custom/order", nil))
+	if got := w.Body.String(); got != `["a"]` {
+		t.Errorf("provided order = %s", got)
+	}
+
+	tm.BuildSlidesFS(fstest.MapFS{
+		"b.png":      {Data: png},
+		"order.json": {Data: []byte(`["b"]`)},
+	}, "json")
+	w = httptest.NewRecorder()
+	tm.(*templates).Router().ServeHTTP(w, httptest.NewRequest("GET", "/json/order", nil))
+	if got := w.Body.String(); got != `["b"]` {
+		t.Errorf("provided order.json = %s", got)
+	}
+
+	tm.BuildSlidesFS(fstest.MapFS{
+		"1.png":     {Data: png},
+		"order.png": {Data: png},
+	}, "shadow")
+	w = httptest.NewRecorder()
+	tm.(*templates).Router().ServeHTTP(w, httptest.NewRequest("GET", "/shadow/order", nil))
+	if got := w.Body.String(); got != `["1"]` {
+		t.Errorf("order with an order.png slide = %s", got)
+	}
+}
+
+func TestExportSlides(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	tm := NewTemplates(zero.New(zero.WithAPIURL("http://api.test")))
+	tm.BuildSlidesFS(fstest.MapFS{
+		"10.png": {Data: png},
+		"2.png":  {Data: png},
+	}, "deck")
+	z := tm.(*templates).Zero
+	cover := zero.One(`<img src="http://api.test/deck/2" alt="cover">`)
+	z.UpdateIndex(&cover)
+
+	dir := t.TempDir()
+	if err := z.Export(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"deck/2.png", "deck/10.png", "deck/order.json", "frames.json", "index.html", "pathless.js"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("missing %s: %v", name, err)
+		}
+	}
+	if order, _ := os.ReadFile(filepath.Join(dir, "deck/order.json")); string(order) != `["2","10"]` {
+		t.Errorf("order.json = %s", order)
+	}
+
+	var m zero.Manifest
+	data, _ := os.ReadFile(filepath.Join(dir, "frames.json"))
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, a := range m.Assets {
+		files[a.Path] = a.File
+	}
+	if files["/deck/order"] != "deck/order.json" || files["/deck/2"] != "deck/2.png" {
+		t.Errorf("manifest assets = %v", files)
+	}
+	last := m.Frames[len(m.Frames)-1]
+	if frame, _ := os.ReadFile(filepath.Join(dir, last.File)); !strings.Contains(string(frame), `src="deck/2.png"`) {
+		t.Errorf("frame not linked to exported file: %s", frame)
+	}
+}
+
+func TestREADMEReload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "2024.md")
+	os.WriteFile(file, []byte("# Old\n"), 0o644)
+
+	tm := NewTemplates(zero.New())
+	z := tm.(*templates).Zero
+	tm.README(file)
+	first, second := zero.One("<p>first</p>"), zero.One("<p>second</p>")
+	z.InsertFrame(0, "first", &first)
+	z.Register("second", &second)
+	z.MoveFrame("frame-2024", 2)
+	z.RemoveFrame("first")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go z.Watch(ctx, 10*time.Millisecond)
+
+	frame := func(key string) string {
+		i, ok := z.Resolve(key)
+		if !ok {
+			t.Fatalf("frame %q not indexed", key)
+		}
+		return string(*z.GetFrame(i))
+	}
+	// Keep editing until the watcher, which starts asynchronously, sees a change.
+	// Edits are spaced out so they settle between notifications.
+	for n := 0; !strings.Contains(frame("frame-2024"), "New"); n++ {
+		if n == 30 {
+			t.Fatal("README frame was not reloaded")
+		}
+		os.WriteFile(file, []byte("# New "+strconv.Itoa(n)+"\n"), 0o644)
+		time.Sleep(100 * time.Millisecond)
+	}
+	if got := frame("second"); got != "<p>second</p>" {
+		t.Errorf("reload replaced another frame: %s", got)
+	}
+}
+
+func TestPlaceByWeight(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, weight string) string {
+		file := filepath.Join(dir, name+".md")
+		os.WriteFile(file, []byte("---\nweight: "+weight+"\n---\n# "+name+"\n"), 0o644)
+		return file
+	}
+	tm := NewTemplates(zero.New())
+	z := tm.(*templates).Zero
+	heavy := write("heavy", "5")
+	tm.README(heavy)
+	plain := write("plain", "0")
+	tm.README(plain)
+	light := write("light", "1")
+	tm.README(light)
+	tm.READMEFS(fstest.MapFS{"bare.md": {Data: []byte("# bare\n")}}, "bare.md")
+
+	order := func() string {
+		var names []string
+		for _, info := range z.Index() {
+			names = append(names, info.Name)
+		}
+		return strings.Join(names, " ")
+	}
+	if got, want := order(), "plain bare light heavy"; got != want {
+		t.Fatalf("order = %q, want %q", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go z.Watch(ctx, 10*time.Millisecond)
+	reload := func(file, weight, want string) {
+		t.Helper()
+		for n := 0; order() != want; n++ {
+			if n == 30 {
+				t.Fatalf("order = %q, want %q", order(), want)
+			}
+			write(strings.TrimSuffix(filepath.Base(file), ".md"), weight)
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	reload(heavy, "0", "plain bare heavy light")
+	reload(light, "-1", "light plain bare heavy")
+	reload(plain, "7", "light bare heavy plain")
+	reload(light, "7", "bare heavy plain light")
+}
+
+func TestCodeCSS(t *testing.T) {
+	tm := NewTemplates(zero.New(zero.WithHighlighting("")))
+	code := tm.READMEFS(fstest.MapFS{"code.md": {Data: []byte("```go\nvar x\n```\n")}}, "code.md")
+	if !strings.Contains(string(*code), `class="chroma"`) || !strings.Contains(string(*code), ".chroma .kn") {
+		t.Errorf("highlighted frame lacks the theme css: %.300s", *code)
+	}
+	plain := tm.READMEFS(fstest.MapFS{"plain.md": {Data: []byte("no code\n")}}, "plain.md")
+	if strings.Contains(string(*plain), ".chroma") {
+		t.Errorf("theme css in a frame without code")
+	}
+}
+
+func TestFrontMatter(t *testing.T) {
+	tm := NewTemplates(zero.New(zero.WithAPIURL("http://api.test")))
+	docs := fstest.MapFS{
+		"intro.md": {Data: []byte("# Intro\n")},
+		"guide.md": {Data: []byte("---\nname: Handbook\ntitle: The Handbook\nclass: wide\nweight: -1\ncss: [/css/extra]\nkeys: {j: next section}\n---\n# Guide\n")},
+		"notes.md": {Data: []byte("+++\ntitle = \"Notes\"\nweight = 5\n+++\nbody\n")},
+		"wip.md":   {Data: []byte("---\ndraft: true\n---\nwip\n")},
+	}
+	tm.READMEFS(docs, "intro.md")
+	tm.READMEFS(docs, "notes.md")
+	tm.READMEFS(docs, "guide.md")
+	tm.READMEFS(docs, "wip.md")
+
+	var names []string
+	for _, info := range tm.(*templates).Index() {
+		names = append(names, info.Name)
+	}
+	if want := []string{"handbook", "intro", "notes"}; !slices.Equal(names, want) {
+		t.Fatalf("index = %v, want %v", names, want)
+	}
+
+	z := tm.(*templates).Zero
+	i, _ := z.Resolve("handbook")
+	info := z.Index()[i]
+	if info.Meta == nil || info.Meta.Title != "The Handbook" || info.Meta.Keys["j"] != "next section" {
+		t.Fatalf("meta = %+v", info.Meta)
+	}
+	frame := string(*z.GetFrame(i))
+	for _, want := range []string{`<div class="text wide">`, `<link rel="stylesheet" href="http://api.test/css/extra">`, `<h1 id="guide">Guide</h1>`} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("frame missing %s:\n%s", want, frame)
+		}
+	}
+	if strings.Contains(frame, "name: Handbook") {
+		t.Error("front matter rendered into the frame")
+	}
+	if n, _ := z.Resolve("notes"); z.Index()[n].Meta.Title != "Notes" {
+		t.Errorf("toml meta = %+v", z.Index()[n].Meta)
+	}
+}
+
+func TestDocs(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	tm := NewTemplates(zero.New(zero.WithAPIURL("http://api.test")))
+	tm.DocsFS(fstest.MapFS{
+		"intro.md":        {Data: []byte("# Welcome\n\nSee [setup](guide/setup.md#install) and [site](https://x.test/a.md).\n\n![diagram](img/arch.png)\n")},
+		"guide/setup.md":  {Data: []byte("---\ntitle: Setup\n---\nBack to [intro](../intro.md).\n")},
+		"guide/draft.md":  {Data: []byte("---\ndraft: true\n---\nwip\n")},
+		"img/arch.png":    {Data: png},
+		".hidden/skip.md": {Data: []byte("# hidden\n")},
+	}, "Handbook")
+
+	z := tm.(*templates).Zero
+	var names []string
+	for _, info := range z.Index() {
+		names = append(names, info.Name)
+	}
+	if want := []string{"handbook", "handbook-intro", "handbook-guide-setup"}; !slices.Equal(names, want) {
+		t.Fatalf("index = %v, want %v", names, want)
+	}
+
+	frame := func(key string) string {
+		i, _ := z.Resolve(key)
+		return string(*z.GetFrame(i))
+	}
+	intro := frame("handbook-intro")
+	for _, want := range []string{`href="#handbook-guide-setup"`, `href="https://x.test/a.md"`, `src="http://api.test/Handbook/img/arch"`} {
+		if !strings.Contains(intro, want) {
+			t.Errorf("intro missing %s:\n%s", want, intro)
+		}
+	}
+	w := httptest.NewRecorder()
+	z.Router().ServeHTTP(w, httptest.NewRequest("GET", "/Handbook/intro", nil))
+	if w.Code != 404 {
+		t.Errorf("markdown source served: %d", w.Code)
+	}
+	if setup := frame("handbook-guide-setup"); !strings.Contains(setup, `href="#handbook-intro"`) {
+		t.Errorf("setup link not rewritten:\n%s", setup)
+	}
+	nav := frame("handbook")
+	want := `<h1>Handbook</h1><ul><li><a href="#handbook-intro">Welcome</a></li><li>guide<ul><li><a href="#handbook-guide-setup">Setup</a></li></ul></li></ul>`
+	if !strings.Contains(nav, want) {
+		t.Errorf("nav = %s\nwant %s", nav, want)
+	}
+}
+
+func TestDocsDir(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	dir := filepath.Join(t.TempDir(), "guide")
+	os.MkdirAll(filepath.Join(dir, "img"), 0o755)
+	os.WriteFile(filepath.Join(dir, "index.md"), []byte("# Home\n\nSee [later](wip.md).\n\n![a](img/a.png)\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "wip.md"), []byte("---\ndraft: true\n---\nsecret\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "img", "a.png"), png, 0o644)
+
+	tm := NewTemplates(zero.New(zero.WithAPIURL("http://api.test")))
+	tm.Docs(dir)
+	z := tm.(*templates).Zero
+	status := func(route string) int {
+		w := httptest.NewRecorder()
+		z.Router().ServeHTTP(w, httptest.NewRequest("GET", route, nil))
+		return w.Code
+	}
+	for route, want := range map[string]int{"/guide/img/a": 200, "/guide/index": 404, "/guide/wip": 404} {
+		if got := status(route); got != want {
+			t.Errorf("GET %s = %d, want %d", route, got, want)
+		}
+	}
+	home := func() string {
+		i, _ := z.Resolve("guide-index")
+		return string(*z.GetFrame(i))
+	}
+	if h := home(); !strings.Contains(h, "See later.") || strings.Contains(h, "wip.md") || !strings.Contains(h, `src="http://api.test/guide/img/a"`) {
+		t.Errorf("home frame:\n%s", h)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go z.Watch(ctx, 10*time.Millisecond)
+	os.WriteFile(filepath.Join(dir, "img", "b.png"), png, 0o644)
+	for n := 0; !strings.Contains(home(), `src="http://api.test/guide/img/b"`); n++ {
+		if n == 30 {
+			t.Fatalf("image added in watch mode not served:\n%s", home())
+		}
+		os.WriteFile(filepath.Join(dir, "index.md"), []byte("# Home "+strconv.Itoa(n)+"\n\n![b](img/b.png)\n"), 0o644)
+		time.Sleep(100 * time.Millisecond)
+	}
+	if got := status("/guide/img/b"); got != 200 {
+		t.Errorf("GET /guide/img/b = %d", got)
+	}
+}
@@ -48,5 +48,5 @@ func (t *templates) Keyboard() {
 })();
 `)
 	html := zero.One(template.HTML(`<div class="grid"></div>`))
-	t.Build("keyboard", true, &html, &css, &js)
+	t.index("keyboard", t.Build("keyboard", false, &html, &css, &js))
 }
@@ -0,0 +1,131 @@
+package zero
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Attribute is an option setting attributes on an element. Every Element method
+// accepts them, and containers also take them alongside their children:
+//
+//	e.Video(src, Controls(), Loop(), Poster("/img/still"))
+//	e.Div("card", Aria("label", "card"), e.H2("title"))
+type Attribute func(*Node)
+
+// Render is empty; Attributes are applied by Append, and by Build to its
+// wrapper, rather than rendered.
+func (Attribute) Render() One { return "" }
+
+// A sets an arbitrary attribute, except event handlers and style; see Node.Attr.
+func A(key, value string) Attribute {
+	return func(n *Node) { n.Attr(key, value) }
+}
+
+// Style adds a CSS declaration to the style attribute; see Node.Style.
+func Style(property, value string) Attribute {
+	return func(n *Node) { n.Style(property, value) }
+}
+
+// Bool sets a boolean attribute, rendered without a value.
+func Bool(key string) Attribute {
+	return func(n *Node) { n.Bool(key, true) }
+}
+
+func ID(id string) Attribute {
+	return func(n *Node) { n.ID(id) }
+}
+
+func Class(classes ...string) Attribute {
+	return func(n *Node) { n.Class(classes...) }
+}
+
+func Data(key, value string) Attribute {
+	return func(n *Node) { n.Data(key, value) }
+}
+
+func Aria(key, value string) Attribute {
+	return func(n *Node) { n.Aria(key, value) }
+}
+
+func Title(title string) Attribute { return A("title", title) }
+func Width(px int) Attribute       { return A("width", strconv.Itoa(px)) }
+func Height(px int) Attribute      { return A("height", strconv.Itoa(px)) }
+func Poster(src string) Attribute  { return A("poster", src) }
+func Role(role string) Attribute   { return A("role", role) }
+
+// Lazy defers loading of images and iframes until they near the viewport.
+func Lazy() Attribute { return A("loading", "lazy") }
+
+func Name(name string) Attribute         { return A("name", name) }
+func Value(value string) Attribute       { return A("value", value) }
+func Placeholder(text string) Attribute  { return A("placeholder", text) }
+func Pattern(re string) Attribute        { return A("pattern", re) }
+func MaxLength(n int) Attribute          { return A("maxlength", strconv.Itoa(n)) }
+func Autocomplete(hint string) Attribute { return A("autocomplete", hint) }
+
+// Start numbers an ordered list from n.
+func Start(n int) Attribute { return A("start", strconv.Itoa(n)) }
+func Reversed() Attribute   { return Bool("reversed") }
+
+func Required() Attribute { return Bool("required") }
+func Checked() Attribute  { return Bool("checked") }
+func Multiple() Attribute { return Bool("multiple") }
+func ReadOnly() Attribute { return Bool("readonly") }
+
+func Controls() Attribute    { return Bool("controls") }
+func Loop() Attribute        { return Bool("loop") }
+func Autoplay() Attribute    { return Bool("autoplay") }
+func Muted() Attribute       { return Bool("muted") }
+func PlaysInline() Attribute { return Bool("playsinline") }
+func Disabled() Attribute    { return Bool("disabled") }
+func Hidden() Attribute      { return Bool("hidden") }
+
+// Sandbox restricts an iframe; with no tokens every restriction applies.
+func Sandbox(tokens ...string) Attribute {
+	return A("sandbox", strings.Join(tokens, " "))
+}
+
+// urlAttrs hold a URL, so their values are checked for a safe scheme.
+var urlAttrs = map[string]bool{
+	"href": true, "src": true, "poster": true, "action": true, "formaction": true,
+	"cite": true, "data": true, "background": true, "manifest": true, "ping": true,
+}
+
+// unsafeURL replaces rejected URLs, matching html/template.
+const unsafeURL = "#ZgotmplZ"
+
+// attrValue filters a value for its attribute context before HTML escaping.
+func attrValue(key, value string) string {
+	switch {
+	case urlAttrs[key]:
+		return safeURL(key, value)
+	case key == "srcset":
+		candidates := strings.Split(value, ",")
+		for i, c := range candidates {
+			c = strings.TrimSpace(c)
+			url, desc, _ := strings.Cut(c, " ")
+			candidates[i] = strings.TrimSpace(safeURL("src", url) + " " + desc)
+		}
+		return strings.Join(candidates, ", ")
+	}
+	return value
+}
+
+// safeURL allows relative URLs and http, https, mailto and tel, plus data:
+// images for attributes that load media.
+func safeURL(key, url string) string {
+	scheme, _, ok := strings.Cut(url, ":")
+	if !ok || strings.ContainsAny(scheme, "/?#") {
+		return url
+	}
+	switch strings.ToLower(strings.TrimSpace(scheme)) {
+	case "http", "https", "mailto", "tel":
+		return url
+	case "data":
+		media := strings.ToLower(url[len(scheme)+1:])
+		if (key == "src" || key == "poster") && strings.HasPrefix(media, "image/") {
+			return url
+		}
+	}
+	return unsafeURL
+}
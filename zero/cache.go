@@ -0,0 +1,110 @@
+package zero
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru is a byte-bounded cache of compressed variants, evicting the least
+// recently used entries first.
+type lru struct {
+	mu      sync.Mutex
+	max     int64
+	size    int64
+	order   *list.List
+	items   map[string]*list.Element
+	loading map[string]*load
+}
+
+// load is a variant being built; callers wanting the same key wait on done.
+type load struct {
+	done chan struct{}
+	data []byte
+	ok   bool
+}
+
+type lruItem struct {
+	key  string
+	data []byte
+	// failed marks a build that failed or did not shrink the content, so it
+	// is not retried while the entry stays cached.
+	failed bool
+}
+
+func newLRU(max int64) *lru {
+	return &lru{max: max, order: list.New(), items: make(map[string]*list.Element), loading: make(map[string]*load)}
+}
+
+// load returns the entry for key, calling build to create it when missing.
+// Concurrent loads of the same key share one build, and a build reporting false
+// is remembered like any other entry rather than retried.
+func (c *lru) load(key string, build func() ([]byte, bool)) ([]byte, bool) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+		item := el.Value.(*lruItem)
+		c.mu.Unlock()
+		return item.data, !item.failed
+	}
+	if l, ok := c.loading[key]; ok {
+		c.mu.Unlock()
+		<-l.done
+		return l.data, l.ok
+	}
+	l := &load{done: make(chan struct{})}
+	c.loading[key] = l
+	c.mu.Unlock()
+
+	l.data, l.ok = build()
+	if l.ok {
+		c.add(key, l.data)
+	} else {
+		c.store(&lruItem{key: key, failed: true})
+	}
+	c.mu.Lock()
+	delete(c.loading, key)
+	c.mu.Unlock()
+	close(l.done)
+	return l.data, l.ok
+}
+
+func (c *lru) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	item := el.Value.(*lruItem)
+	return item.data, !item.failed
+}
+
+// add stores data under key unless it alone exceeds the cache size.
+func (c *lru) add(key string, data []byte) {
+	c.store(&lruItem{key: key, data: data})
+}
+
+func (c *lru) store(item *lruItem) {
+	n := int64(len(item.data))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n > c.max {
+		return
+	}
+	if el, ok := c.items[item.key]; ok {
+		c.size -= int64(len(el.Value.(*lruItem).data))
+		el.Value = item
+		c.order.MoveToFront(el)
+	} else {
+		c.items[item.key] = c.order.PushFront(item)
+	}
+	c.size += n
+	for c.size > c.max {
+		el := c.order.Back()
+		old := el.Value.(*lruItem)
+		c.order.Remove(el)
+		delete(c.items, old.key)
+		c.size -= int64(len(old.data))
+	}
+}
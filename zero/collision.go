@@ -0,0 +1,43 @@
+package zero
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// CollisionPolicy decides which file serves a route claimed by two source files,
+// e.g. logo.png and logo.webp, which both map to /<dir>/logo. It returns the path
+// to keep, and an error when the collision should be reported to the caller.
+type CollisionPolicy func(route, existing, candidate string) (string, error)
+
+// CollisionError keeps the existing file and reports the collision.
+func CollisionError(route, existing, candidate string) (string, error) {
+	return existing, fmt.Errorf("route %s: %s collides with %s", route, candidate, existing)
+}
+
+// FirstWins keeps the existing file and ignores the candidate.
+func FirstWins(route, existing, candidate string) (string, error) {
+	return existing, nil
+}
+
+// PreferExt keeps the file whose extension comes first in exts, e.g.
+// PreferExt(".webp", ".png"). Unlisted extensions rank last; ties keep the existing file.
+func PreferExt(exts ...string) CollisionPolicy {
+	rank := func(path string) int {
+		i := slices.IndexFunc(exts, func(ext string) bool {
+			return strings.EqualFold(ext, filepath.Ext(path))
+		})
+		if i < 0 {
+			return len(exts)
+		}
+		return i
+	}
+	return func(route, existing, candidate string) (string, error) {
+		if rank(candidate) < rank(existing) {
+			return candidate, nil
+		}
+		return existing, nil
+	}
+}
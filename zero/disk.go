@@ -0,0 +1,133 @@
+package zero
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// diskFile is an asset too large to hold in memory. It is read from its file
+// system on each request; compressed variants are built on demand and kept in a
+// shared LRU. The file may change under it, so its validators are checked
+// against the file's size and modification time on each request.
+type diskFile struct {
+	fsys      fs.FS
+	name      string
+	encodings []string
+
+	mu      sync.Mutex
+	size    int64
+	modTime time.Time
+	hash    string
+}
+
+func newDiskFile(fsys fs.FS, name string, info fs.FileInfo, encodings []string) (*diskFile, error) {
+	hash, err := hashFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return &diskFile{
+		fsys:      fsys,
+		name:      name,
+		size:      info.Size(),
+		modTime:   info.ModTime(),
+		hash:      hash,
+		encodings: encodings,
+	}, nil
+}
+
+func hashFile(fsys fs.FS, name string) (string, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	sum := sha256.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)[:8]), nil
+}
+
+// validators returns the file's hash, size and modification time as last seen.
+func (d *diskFile) validators() (string, int64, time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.hash, d.size, d.modTime
+}
+
+// refresh rehashes the file when the open file's size or modification time no
+// longer match the validators, and returns them.
+func (d *diskFile) refresh(file fs.File) (string, int64, time.Time, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return "", 0, time.Time{}, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if info.Size() != d.size || !info.ModTime().Equal(d.modTime) {
+		hash, err := hashFile(d.fsys, d.name)
+		if err != nil {
+			return "", 0, time.Time{}, err
+		}
+		d.hash, d.size, d.modTime = hash, info.Size(), info.ModTime()
+	}
+	return d.hash, d.size, d.modTime, nil
+}
+
+func (d *diskFile) serve(w http.ResponseWriter, r *http.Request, cache *lru) {
+	file, err := d.fsys.Open(d.name)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+	hash, size, modTime, err := d.refresh(file)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+	// Only compress what the cache can hold; anything larger streams as identity.
+	if r.Header.Get("Range") == "" && size <= cache.max {
+		if enc := negotiate(r.Header.Get("Accept-Encoding"), d.encodings); enc != "" {
+			if body, ok := d.variant(hash, size, enc, cache); ok {
+				serveVariant(w, r, hash, modTime, body, enc)
+				return
+			}
+		}
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	serveIdentity(w, r, hash, modTime, content)
+}
+
+// variant returns the content with hash compressed with enc, reporting false if
+// compression failed or did not make it smaller. Variants are keyed by content
+// hash, so an edited file never gets a stale one, and each is built once however
+// many requests ask for it at the same time; a variant that could not be built is
+// remembered too, so later requests go straight to identity.
+func (d *diskFile) variant(hash string, size int64, enc string, cache *lru) ([]byte, bool) {
+	return cache.load(hash+"-"+enc, func() ([]byte, bool) {
+		file, err := d.fsys.Open(d.name)
+		if err != nil {
+			return nil, false
+		}
+		defer file.Close()
+		var buf bytes.Buffer
+		if err := compress(&buf, file, enc, compressFast); err != nil || int64(buf.Len()) >= size {
+			return nil, false
+		}
+		return buf.Bytes(), true
+	})
+}
@@ -0,0 +1,192 @@
+package zero
+
+import (
+	"compress/gzip"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRUEviction(t *testing.T) {
+	c := newLRU(10)
+	c.add("a", make([]byte, 4))
+	c.add("b", make([]byte, 4))
+	c.get("a")
+	c.add("c", make([]byte, 4))
+	if _, ok := c.get("b"); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Error("recently used entry was evicted")
+	}
+	c.add("huge", make([]byte, 11))
+	if _, ok := c.get("huge"); ok {
+		t.Error("entry larger than the cache was stored")
+	}
+	if c.size > c.max {
+		t.Errorf("size %d exceeds max %d", c.size, c.max)
+	}
+}
+
+func TestDiskAsset(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "docs")
+	os.Mkdir(dir, 0o755)
+	content := strings.Repeat("streamed from disk ", 100)
+	os.WriteFile(filepath.Join(dir, "big.txt"), []byte(content), 0o644)
+	os.WriteFile(filepath.Join(dir, "small.txt"), []byte("small"), 0o644)
+
+	z := New(WithDiskThreshold(100), WithCacheSize(1<<20))
+	z.AddPath(dir)
+	f := z.(*zeroImpl).Fx.(*fx)
+	if f.assets["/docs/big"].file == nil || f.assets["/docs/small"].file != nil {
+		t.Fatal("threshold did not split disk and memory assets")
+	}
+
+	get := func(headers ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/docs/big", nil)
+		for i := 0; i < len(headers); i += 2 {
+			r.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		z.Router().ServeHTTP(w, r)
+		return w
+	}
+	if w := get("Range", "bytes=0-7"); w.Code != http.StatusPartialContent || w.Body.String() != content[:8] {
+		t.Errorf("range: got %d %q", w.Code, w.Body.String())
+	}
+	w := get("Accept-Encoding", "gzip")
+	if w.Header().Get("Content-Encoding") != Gzip || w.Body.Len() >= len(content) {
+		t.Errorf("gzip: got encoding %q with %d bytes", w.Header().Get("Content-Encoding"), w.Body.Len())
+	}
+	if _, ok := f.variants.get(f.assets["/docs/big"].file.hash + "-gzip"); !ok {
+		t.Error("compressed variant was not cached")
+	}
+	if w := get("Accept-Encoding", "gzip", "If-None-Match", w.Header().Get("ETag")); w.Code != http.StatusNotModified {
+		t.Errorf("revalidation: got %d", w.Code)
+	}
+}
+
+func TestDiskAssetEdited(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "docs")
+	os.Mkdir(dir, 0o755)
+	file := filepath.Join(dir, "big.txt")
+	os.WriteFile(file, []byte(strings.Repeat("first version ", 100)), 0o644)
+
+	z := New(WithDiskThreshold(100), WithCacheSize(1<<20))
+	z.AddPath(dir)
+	get := func(enc string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/docs/big", nil)
+		r.Header.Set("Accept-Encoding", enc)
+		w := httptest.NewRecorder()
+		z.Router().ServeHTTP(w, r)
+		return w
+	}
+	before, beforeGzip := get(""), get("gzip")
+
+	edited := strings.Repeat("second version ", 100)
+	os.WriteFile(file, []byte(edited), 0o644)
+	os.Chtimes(file, time.Now().Add(time.Hour), time.Now().Add(time.Hour))
+
+	after, afterGzip := get(""), get("gzip")
+	if after.Body.String() != edited {
+		t.Fatal("identity body not read from the edited file")
+	}
+	if after.Header().Get("ETag") == before.Header().Get("ETag") || after.Header().Get("Last-Modified") == before.Header().Get("Last-Modified") {
+		t.Errorf("validators unchanged after edit: %v", after.Header())
+	}
+	if afterGzip.Header().Get("ETag") == beforeGzip.Header().Get("ETag") {
+		t.Error("compressed variant served from the old content")
+	}
+	zr, err := gzip.NewReader(afterGzip.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != edited {
+		t.Error("compressed variant does not match the edited file")
+	}
+}
+
+func TestLRULoadFailureCached(t *testing.T) {
+	c := newLRU(1 << 10)
+	var builds int
+	for range 3 {
+		if _, ok := c.load("k", func() ([]byte, bool) {
+			builds++
+			return nil, false
+		}); ok {
+			t.Fatal("failed build reported ok")
+		}
+	}
+	if builds != 1 {
+		t.Errorf("built %d times, want 1", builds)
+	}
+}
+
+func TestDiskIncompressibleBuiltOnce(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "lib")
+	os.Mkdir(dir, 0o755)
+	data := make([]byte, 4096)
+	rand.Read(data)
+	os.WriteFile(filepath.Join(dir, "app.jar"), data, 0o644)
+
+	z := New(WithDiskThreshold(100), WithCacheSize(1<<20))
+	z.AddPath(dir)
+	f := z.(*zeroImpl).Fx.(*fx)
+	file := f.assets["/lib/app"].file
+	if file == nil {
+		t.Fatal("app.jar not served from disk")
+	}
+	var builds int
+	build := func() ([]byte, bool) {
+		builds++
+		return nil, false
+	}
+	for range 3 {
+		r := httptest.NewRequest("GET", "/lib/app", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		z.Router().ServeHTTP(w, r)
+		if w.Header().Get("Content-Encoding") != "" || w.Body.Len() != len(data) {
+			t.Fatalf("got encoding %q with %d bytes", w.Header().Get("Content-Encoding"), w.Body.Len())
+		}
+	}
+	// The served requests left a marker, so a further load does not build.
+	if _, ok := f.variants.load(file.hash+"-gzip", build); ok || builds != 0 {
+		t.Errorf("incompressible variant rebuilt %d times", builds)
+	}
+}
+
+func TestLRULoadShared(t *testing.T) {
+	c := newLRU(1 << 10)
+	var builds atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, ok := c.load("k", func() ([]byte, bool) {
+				builds.Add(1)
+				<-release
+				return []byte("v"), true
+			})
+			if !ok || string(data) != "v" {
+				t.Errorf("load = %q, %v", data, ok)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := builds.Load(); n != 1 {
+		t.Errorf("built %d times, want 1", n)
+	}
+}
@@ -1,13 +1,9 @@
 package zero
 
 import (
-	"fmt"
-	"html"
-	"html/template"
 	"path/filepath"
 	"strings"
 
-	math "github.com/litao91/goldmark-mathjax"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
@@ -16,229 +12,317 @@ import (
 
 type Element interface {
 	Markdown() *goldmark.Markdown
-	H1(s string) *One
-	H2(s string) *One
-	H3(s string) *One
-	H4(s string) *One
-	H5(s string) *One
-	H6(s string) *One
-	Paragraph(s string) *One
-	Span(s string) *One
-	Strong(s string) *One
-	Em(s string) *One
-	Small(s string) *One
-	Mark(s string) *One
-	Del(s string) *One
-	Ins(s string) *One
-	Sub(s string) *One
-	Sup(s string) *One
-	Kbd(s string) *One
-	Samp(s string) *One
-	VarElem(s string) *One
-	Abbr(s string) *One
-	Time(s string) *One
-	Button(label string) *One
-	Code(code string) *One
-	CodeBlock(lang, code string) *One
-
-	Div(class string, children ...*One) *One
-	Link(href, text string) *One
-	LinkedImg(href, src, alt string) *One
-	LinkedIcon(href, src, alt string) *One
-	List(items []any, ordered bool) *One
-	Img(src, alt string) *One
-	Video(src string) *One
-	Audio(src string) *One
-	Iframe(src string) *One
-	Embed(src string) *One
-	Source(src string) *One
-	Canvas(id string) *One
-	Table(cols uint8, rows uint64, data [][]string) *One
+	HighlightStyle() string
+	H1(s string, attrs ...Attribute) *Node
+	H2(s string, attrs ...Attribute) *Node
+	H3(s string, attrs ...Attribute) *Node
+	H4(s string, attrs ...Attribute) *Node
+	H5(s string, attrs ...Attribute) *Node
+	H6(s string, attrs ...Attribute) *Node
+	Paragraph(s string, attrs ...Attribute) *Node
+	Span(s string, attrs ...Attribute) *Node
+	Strong(s string, attrs ...Attribute) *Node
+	Em(s string, attrs ...Attribute) *Node
+	Small(s string, attrs ...Attribute) *Node
+	Mark(s string, attrs ...Attribute) *Node
+	Del(s string, attrs ...Attribute) *Node
+	Ins(s string, attrs ...Attribute) *Node
+	Sub(s string, attrs ...Attribute) *Node
+	Sup(s string, attrs ...Attribute) *Node
+	Kbd(s string, attrs ...Attribute) *Node
+	Samp(s string, attrs ...Attribute) *Node
+	VarElem(s string, attrs ...Attribute) *Node
+	Abbr(s string, attrs ...Attribute) *Node
+	Time(s string, attrs ...Attribute) *Node
+	Button(label string, attrs ...Attribute) *Node
+	Code(code string, attrs ...Attribute) *Node
+	CodeBlock(lang, code string, attrs ...Attribute) *Node
+
+	Div(class string, children ...Renderable) *Node
+	Link(href, text string, attrs ...Attribute) *Node
+	LinkedImg(href, src, alt string, attrs ...Attribute) *Node
+	LinkedIcon(href, src, alt string, attrs ...Attribute) *Node
+	List(items []any, ordered bool, attrs ...Attribute) *Node
+	DescriptionList(entries []Description, attrs ...Attribute) *Node
+	Img(src, alt string, attrs ...Attribute) *Node
+	Video(src string, attrs ...Attribute) *Node
+	Audio(src string, attrs ...Attribute) *Node
+	Iframe(src string, attrs ...Attribute) *Node
+	Embed(src string, attrs ...Attribute) *Node
+	Source(src string, attrs ...Attribute) *Node
+	Canvas(id string, attrs ...Attribute) *Node
+	Table(cols uint8, rows uint64, data [][]string, attrs ...Attribute) *Node
+
+	Form(action string, children ...Renderable) *Node
+	Fieldset(legend string, children ...Renderable) *Node
+	Label(forID, text string, attrs ...Attribute) *Node
+	Input(kind, name string, attrs ...Attribute) *Node
+	Textarea(name, text string, attrs ...Attribute) *Node
+	Select(name string, options []string, attrs ...Attribute) *Node
 }
 
 // --- element Implementation ---
 type element struct {
-	Md *goldmark.Markdown
+	Md        *goldmark.Markdown
+	highlight *highlighter
+	images    *images
 }
 
 func NewElement() Element {
+	return newElement(newConfig())
+}
+
+func newElement(c *config) *element {
+	h := newHighlighter(c)
+	img := &images{apiURL: c.apiURL}
+	extensions := append([]goldmark.Extender{img}, c.extensions...)
+	if h != nil {
+		extensions = append([]goldmark.Extender{h.extension()}, extensions...)
+	}
 	return &element{
-		Md: initGoldmark(),
+		Md:        initGoldmark(mathExtension(c.math), extensions...),
+		highlight: h,
+		images:    img,
 	}
 }
-func Tag(tag, text string) *One {
-	o := One(template.HTML(fmt.Sprintf("<%s>%s</%s>", tag, html.EscapeString(text), tag)))
-	return &o
-}
 
 func (e *element) Markdown() *goldmark.Markdown {
 	return e.Md
 }
 
-func (e *element) H1(s string) *One        { return Tag("h1", s) }
-func (e *element) H2(s string) *One        { return Tag("h2", s) }
-func (e *element) H3(s string) *One        { return Tag("h3", s) }
-func (e *element) H4(s string) *One        { return Tag("h4", s) }
-func (e *element) H5(s string) *One        { return Tag("h5", s) }
-func (e *element) H6(s string) *One        { return Tag("h6", s) }
-func (e *element) Paragraph(s string) *One { return Tag("p", s) }
-func (e *element) Span(s string) *One      { return Tag("span", s) }
-func (e *element) Strong(s string) *One    { return Tag("strong", s) }
-func (e *element) Em(s string) *One        { return Tag("em", s) }
-func (e *element) Small(s string) *One     { return Tag("small", s) }
-func (e *element) Mark(s string) *One      { return Tag("mark", s) }
-func (e *element) Del(s string) *One       { return Tag("del", s) }
-func (e *element) Ins(s string) *One       { return Tag("ins", s) }
-func (e *element) Sub(s string) *One       { return Tag("sub", s) }
-func (e *element) Sup(s string) *One       { return Tag("sup", s) }
-func (e *element) Kbd(s string) *One       { return Tag("kbd", s) }
-func (e *element) Samp(s string) *One      { return Tag("samp", s) }
-func (e *element) VarElem(s string) *One   { return Tag("var", s) }
-
-func (e *element) Abbr(s string) *One       { return Tag("abbr", s) }
-func (e *element) Time(s string) *One       { return Tag("time", s) }
-func (e *element) Button(label string) *One { return Tag("button", label) }
-func (e *element) Code(code string) *One    { return Tag("code", code) }
-
-// CodeBlock wraps the code string in <pre><code class="language-xxx">...</code></pre> for block display.
-// Usage: e.CodeBlock("javascript", `console.log("hi")`)
-func (e *element) CodeBlock(lang, code string) *One {
-	class := ""
-	if lang != "" {
-		class = fmt.Sprintf(` class="language-%s"`, html.EscapeString(lang))
+// HighlightStyle is the chroma style code is highlighted with, "" when off.
+func (e *element) HighlightStyle() string {
+	if e.highlight == nil {
+		return ""
 	}
-	o := One(template.HTML(fmt.Sprintf(
-		`<pre><code%s>%s</code></pre>`,
-		class,
-		html.EscapeString(code),
-	)))
-	return &o
-}
-
-func (e *element) Div(class string, children ...*One) *One {
-	var b strings.Builder
-	b.WriteString(fmt.Sprintf(`<div class="%s">`, html.EscapeString(class)))
-	for _, child := range children {
-		if child != nil {
-			b.WriteString(string(*child))
-		}
-	}
-	b.WriteString("</div>")
-	o := One(template.HTML(b.String()))
-	return &o
+	return e.highlight.style
 }
 
-func (e *element) LinkedImg(href, src, alt string) *One {
-	if alt == "" {
-		parts := strings.Split(src, "/")
-		fileName := parts[len(parts)-1]
-		alt = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+func (e *element) H1(s string, attrs ...Attribute) *Node        { return Tag("h1", s).With(attrs...) }
+func (e *element) H2(s string, attrs ...Attribute) *Node        { return Tag("h2", s).With(attrs...) }
+func (e *element) H3(s string, attrs ...Attribute) *Node        { return Tag("h3", s).With(attrs...) }
+func (e *element) H4(s string, attrs ...Attribute) *Node        { return Tag("h4", s).With(attrs...) }
+func (e *element) H5(s string, attrs ...Attribute) *Node        { return Tag("h5", s).With(attrs...) }
+func (e *element) H6(s string, attrs ...Attribute) *Node        { return Tag("h6", s).With(attrs...) }
+func (e *element) Paragraph(s string, attrs ...Attribute) *Node { return Tag("p", s).With(attrs...) }
+func (e *element) Span(s string, attrs ...Attribute) *Node      { return Tag("span", s).With(attrs...) }
+func (e *element) Strong(s string, attrs ...Attribute) *Node    { return Tag("strong", s).With(attrs...) }
+func (e *element) Em(s string, attrs ...Attribute) *Node        { return Tag("em", s).With(attrs...) }
+func (e *element) Small(s string, attrs ...Attribute) *Node     { return Tag("small", s).With(attrs...) }
+func (e *element) Mark(s string, attrs ...Attribute) *Node      { return Tag("mark", s).With(attrs...) }
+func (e *element) Del(s string, attrs ...Attribute) *Node       { return Tag("del", s).With(attrs...) }
+func (e *element) Ins(s string, attrs ...Attribute) *Node       { return Tag("ins", s).With(attrs...) }
+func (e *element) Sub(s string, attrs ...Attribute) *Node       { return Tag("sub", s).With(attrs...) }
+func (e *element) Sup(s string, attrs ...Attribute) *Node       { return Tag("sup", s).With(attrs...) }
+func (e *element) Kbd(s string, attrs ...Attribute) *Node       { return Tag("kbd", s).With(attrs...) }
+func (e *element) Samp(s string, attrs ...Attribute) *Node      { return Tag("samp", s).With(attrs...) }
+func (e *element) VarElem(s string, attrs ...Attribute) *Node   { return Tag("var", s).With(attrs...) }
+
+func (e *element) Abbr(s string, attrs ...Attribute) *Node { return Tag("abbr", s).With(attrs...) }
+func (e *element) Time(s string, attrs ...Attribute) *Node { return Tag("time", s).With(attrs...) }
+func (e *element) Button(label string, attrs ...Attribute) *Node {
+	return Tag("button", label).With(attrs...)
+}
+func (e *element) Code(code string, attrs ...Attribute) *Node {
+	return Tag("code", code).With(attrs...)
+}
+
+// CodeBlock highlights code on the server when highlighting is on, wrapped in a
+// <div class="highlight"> that takes the attributes; LineNumbers and HighlightLines
+// control the gutter and marked lines. With highlighting off it wraps the code in
+// <pre><code class="language-xxx">...</code></pre> for a client-side highlighter.
+// Usage: e.CodeBlock("javascript", `console.log("hi")`, HighlightLines("1"))
+func (e *element) CodeBlock(lang, code string, attrs ...Attribute) *Node {
+	if e.highlight != nil {
+		wrapper := El("div").Class("highlight").With(attrs...)
+		lines, numbers := codeOptions(wrapper)
+		out, err := e.highlight.highlight(lang, code, numbers || e.highlight.lineNumbers, parseLines(lines))
+		if err == nil {
+			return wrapper.Append(&out)
+		}
 	}
-	o := One(template.HTML(fmt.Sprintf(
-		`<a href="%s" target="_blank" rel="noopener"><img src="%s" alt="%s></a>`,
-		html.EscapeString(href),
-		html.EscapeString(src),
-		html.EscapeString(alt),
-	)))
-	return &o
-}
-func (e *element) LinkedIcon(href, src, alt string) *One {
-	if alt == "" {
-		parts := strings.Split(src, "/")
-		fileName := parts[len(parts)-1]
-		alt = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	c := Tag("code", code)
+	if lang != "" {
+		c.Class("language-" + lang)
 	}
-	o := One(template.HTML(fmt.Sprintf(
-		`<a href="%s" target="_blank" rel="noopener"><img src="%s" alt="%s "class="icon"></a>`,
-		html.EscapeString(href),
-		html.EscapeString(src),
-		html.EscapeString(alt),
-	)))
-	return &o
+	pre := El("pre").With(attrs...)
+	codeOptions(pre)
+	return pre.Append(c)
+}
+
+// codeOptions takes the LineNumbers and HighlightLines settings off n.
+func codeOptions(n *Node) (lines string, numbers bool) {
+	lines, _ = n.Get("data-hl-lines")
+	_, numbers = n.Get("data-linenos")
+	n.Remove("data-hl-lines").Remove("data-linenos")
+	return lines, numbers
 }
 
-func (e *element) Link(href, text string) *One {
-	o := One(template.HTML(fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(href), html.EscapeString(text))))
-	return &o
+// Div accepts Attributes among its children.
+func (e *element) Div(class string, children ...Renderable) *Node {
+	return El("div").Attr("class", class).Append(children...)
 }
 
-func (e *element) Img(src, alt string) *One {
+// LinkedImg opens href in a new tab. Attributes apply to the <img>.
+func (e *element) LinkedImg(href, src, alt string, attrs ...Attribute) *Node {
+	return El("a", e.Img(src, alt, attrs...)).Attr("href", href).Attr("target", "_blank").Attr("rel", "noopener")
+}
+
+func (e *element) LinkedIcon(href, src, alt string, attrs ...Attribute) *Node {
+	return e.LinkedImg(href, src, alt, append([]Attribute{Class("icon")}, attrs...)...)
+}
+
+func (e *element) Link(href, text string, attrs ...Attribute) *Node {
+	return Tag("a", text).Attr("href", href).With(attrs...)
+}
+
+// Img defaults alt to the file name without its extension.
+func (e *element) Img(src, alt string, attrs ...Attribute) *Node {
 	if alt == "" {
 		parts := strings.Split(src, "/")
 		fileName := parts[len(parts)-1]
 		alt = strings.TrimSuffix(fileName, filepath.Ext(fileName))
 	}
-	o := One(template.HTML(fmt.Sprintf(
-		`<img src="%s" alt="%s">`,
-		html.EscapeString(src),
-		html.EscapeString(alt),
-	)))
-	return &o
+	return El("img").Attr("src", src).Attr("alt", alt).With(attrs...)
 }
 
-func (e *element) List(items []any, ordered bool) *One {
+// List renders items as <li>s. Renderables such as *One and *Node are placed
+// verbatim, a nested []any becomes a sub-list of the same kind inside the
+// preceding item, a Task becomes a GFM task-list item, and anything else is
+// escaped text. Start and Reversed apply to ordered lists.
+func (e *element) List(items []any, ordered bool, attrs ...Attribute) *Node {
 	tag := "ul"
 	if ordered {
 		tag = "ol"
 	}
-	var b strings.Builder
-	b.WriteString(fmt.Sprintf("<%s>", tag))
+	list := El(tag)
+	var last *Node
 	for _, item := range items {
-		b.WriteString(fmt.Sprintf("<li>%v</li>", html.EscapeString(fmt.Sprintf("%v", item))))
+		switch item := item.(type) {
+		case []any:
+			sub := e.List(item, ordered)
+			if last == nil {
+				last = El("li")
+				list.Append(last)
+			}
+			last.Append(sub)
+			continue
+		case Task:
+			last = item.node()
+		default:
+			last = El("li", renderable(item))
+		}
+		list.Append(last)
 	}
-	b.WriteString(fmt.Sprintf("</%s>", tag))
-	o := One(template.HTML(b.String()))
-	return &o
+	return list.With(attrs...)
 }
 
-func (e *element) Video(src string) *One {
-	o := One(template.HTML(fmt.Sprintf(`<video src="%s"></video>`, html.EscapeString(src))))
-	return &o
+// Task is a task-list item rendered like GFM's "- [x] item".
+type Task struct {
+	Done bool
+	Item any
 }
 
-func (e *element) Audio(src string) *One {
-	o := One(template.HTML(fmt.Sprintf(`<audio src="%s"></audio>`, html.EscapeString(src))))
-	return &o
+func (t Task) node() *Node {
+	box := El("input")
+	if t.Done {
+		box.Attr("checked", "")
+	}
+	box.Attr("disabled", "").Attr("type", "checkbox")
+	return El("li", box, Text(" "), renderable(t.Item))
 }
 
-func (e *element) Iframe(src string) *One {
-	o := One(template.HTML(fmt.Sprintf(`<iframe src="%s"></iframe>`, html.EscapeString(src))))
-	return &o
+// Description is a <dl> group: a term and its details.
+type Description struct {
+	Term    any
+	Details []any
 }
 
-func (e *element) Embed(src string) *One {
-	o := One(template.HTML(fmt.Sprintf(`<embed src="%s"/>`, html.EscapeString(src))))
-	return &o
+// DescriptionList renders a <dl>; terms and details follow List's item rules.
+func (e *element) DescriptionList(entries []Description, attrs ...Attribute) *Node {
+	dl := El("dl")
+	for _, d := range entries {
+		dl.Append(El("dt", renderable(d.Term)))
+		for _, detail := range d.Details {
+			dl.Append(El("dd", renderable(detail)))
+		}
+	}
+	return dl.With(attrs...)
 }
 
-func (e *element) Source(src string) *One {
-	o := One(template.HTML(fmt.Sprintf(`<source src="%s"/>`, html.EscapeString(src))))
-	return &o
+func (e *element) Video(src string, attrs ...Attribute) *Node  { return media("video", src, attrs) }
+func (e *element) Audio(src string, attrs ...Attribute) *Node  { return media("audio", src, attrs) }
+func (e *element) Iframe(src string, attrs ...Attribute) *Node { return media("iframe", src, attrs) }
+func (e *element) Embed(src string, attrs ...Attribute) *Node  { return media("embed", src, attrs) }
+func (e *element) Source(src string, attrs ...Attribute) *Node { return media("source", src, attrs) }
+func (e *element) Canvas(id string, attrs ...Attribute) *Node {
+	return El("canvas").ID(id).With(attrs...)
 }
 
-func (e *element) Canvas(id string) *One {
-	o := One(template.HTML(fmt.Sprintf(`<canvas id="%s"></canvas>`, html.EscapeString(id))))
-	return &o
+func media(tag, src string, attrs []Attribute) *Node {
+	return El(tag).Attr("src", src).With(attrs...)
 }
 
-func (e *element) Table(cols uint8, rows uint64, data [][]string) *One {
-	var b strings.Builder
-	b.WriteString("<table>")
-	for _, row := range data {
-		b.WriteString("<tr>")
-		for _, cell := range row {
-			b.WriteString(fmt.Sprintf("<td>%s</td>", html.EscapeString(cell)))
+// Table renders data without a header row. A non-zero cols pads or truncates
+// every row to that many cells and a non-zero rows limits the row count; use
+// NewTable and the TableFrom constructors for headers, captions and sorting.
+func (e *element) Table(cols uint8, rows uint64, data [][]string, attrs ...Attribute) *Node {
+	if rows > 0 && uint64(len(data)) > rows {
+		data = data[:rows]
+	}
+	if cols > 0 {
+		shaped := make([][]string, len(data))
+		for i, row := range data {
+			shaped[i] = make([]string, cols)
+			copy(shaped[i], row)
 		}
-		b.WriteString("</tr>")
+		data = shaped
+	}
+	return NewTable(nil, data).With(attrs...).Node()
+}
+
+// Form posts to action, usually the URL returned by AddForm. The runtime submits
+// it in the background and shows the result in its [data-form-status] element.
+func (e *element) Form(action string, children ...Renderable) *Node {
+	return El("form").Attr("method", "post").Attr("action", action).Bool("data-form", true).Append(children...)
+}
+
+func (e *element) Fieldset(legend string, children ...Renderable) *Node {
+	fs := El("fieldset")
+	if legend != "" {
+		fs.Append(Tag("legend", legend))
+	}
+	return fs.Append(children...)
+}
+
+func (e *element) Label(forID, text string, attrs ...Attribute) *Node {
+	return Tag("label", text).Attr("for", forID).With(attrs...)
+}
+
+// Input, Textarea and Select take their id from name so a Label can point at them.
+func (e *element) Input(kind, name string, attrs ...Attribute) *Node {
+	return El("input").Attr("type", kind).Attr("name", name).ID(name).With(attrs...)
+}
+
+func (e *element) Textarea(name, text string, attrs ...Attribute) *Node {
+	return El("textarea").Attr("name", name).ID(name).Text(text).With(attrs...)
+}
+
+// Select uses each option as both value and label. A Value attribute selects
+// the matching option.
+func (e *element) Select(name string, options []string, attrs ...Attribute) *Node {
+	sel := El("select").Attr("name", name).ID(name).With(attrs...)
+	selected, ok := sel.Get("value")
+	sel.Remove("value")
+	for _, opt := range options {
+		sel.Append(Tag("option", opt).Attr("value", opt).Bool("selected", ok && opt == selected))
 	}
-	b.WriteString("</table>")
-	o := One(template.HTML(b.String()))
-	return &o
+	return sel
 }
 
-func initGoldmark() *goldmark.Markdown {
+func initGoldmark(math goldmark.Extender, extensions ...goldmark.Extender) *goldmark.Markdown {
 	md := goldmark.New(
-		goldmark.WithExtensions(extension.GFM, math.MathJax),
+		goldmark.WithExtensions(append([]goldmark.Extender{extension.GFM, math, &frontMatter{}}, extensions...)...),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
 			parser.WithAttribute(),
@@ -0,0 +1,270 @@
+package zero
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content codings a body can be compressed with, in order of server preference.
+const (
+	Brotli = "br"
+	Zstd   = "zstd"
+	Gzip   = "gzip"
+)
+
+var defaultEncodings = []string{Brotli, Zstd, Gzip}
+
+// Compression effort: best for content compressed once and kept for the life
+// of the body, fast for variants that may be evicted and rebuilt.
+const (
+	compressBest = iota
+	compressFast
+)
+
+// encoded is a response body with its compressed variants and validators.
+// Each variant is compressed on the first request that negotiates it; variants
+// that would not be smaller than the identity bytes are never sent.
+type encoded struct {
+	identity []byte
+	variants map[string]*variant
+	order    []string
+	hash     string
+	modTime  time.Time
+}
+
+// variant is one lazily compressed coding of an encoded body.
+type variant struct {
+	once sync.Once
+	body []byte // nil when not smaller than the identity bytes
+}
+
+// contentHash is a short, stable fingerprint of content.
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+func encode(data []byte, encodings []string) *encoded {
+	e := &encoded{
+		identity: data,
+		variants: make(map[string]*variant, len(encodings)),
+		order:    encodings,
+		hash:     contentHash(data),
+		modTime:  time.Now(),
+	}
+	for _, enc := range encodings {
+		e.variants[enc] = &variant{}
+	}
+	return e
+}
+
+// variant returns the body compressed with enc, compressing it on first use.
+func (e *encoded) variant(enc string) []byte {
+	v := e.variants[enc]
+	v.once.Do(func() {
+		var buf bytes.Buffer
+		if err := compress(&buf, bytes.NewReader(e.identity), enc, compressBest); err == nil && buf.Len() < len(e.identity) {
+			v.body = buf.Bytes()
+		}
+	})
+	return v.body
+}
+
+// compress streams src into dst with the content coding enc at the given effort.
+func compress(dst io.Writer, src io.Reader, enc string, effort int) error {
+	var w io.WriteCloser
+	switch enc {
+	case Brotli:
+		level := 9
+		if effort == compressFast {
+			level = 4
+		}
+		w = brotli.NewWriterLevel(dst, level)
+	case Zstd:
+		level := zstd.SpeedBetterCompression
+		if effort == compressFast {
+			level = zstd.SpeedDefault
+		}
+		zw, err := zstd.NewWriter(dst, zstd.WithEncoderLevel(level))
+		if err != nil {
+			return err
+		}
+		w = zw
+	case Gzip:
+		level := gzip.BestCompression
+		if effort == compressFast {
+			level = gzip.DefaultCompression
+		}
+		w, _ = gzip.NewWriterLevel(dst, level)
+	default:
+		return fmt.Errorf("unsupported content coding %q", enc)
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// body returns the variant best matching the request's Accept-Encoding and its coding,
+// "" for identity. Range requests always get identity so byte offsets stay meaningful.
+func (e *encoded) body(r *http.Request) ([]byte, string) {
+	if r.Header.Get("Range") != "" {
+		return e.identity, ""
+	}
+	accept, available := r.Header.Get("Accept-Encoding"), e.order
+	for {
+		enc := negotiate(accept, available)
+		if enc == "" {
+			return e.identity, ""
+		}
+		if body := e.variant(enc); body != nil {
+			return body, enc
+		}
+		available = slices.DeleteFunc(slices.Clone(available), func(s string) bool { return s == enc })
+	}
+}
+
+// etag is the strong validator of the representation sent with coding enc.
+func (e *encoded) etag(enc string) string {
+	return etag(e.hash, enc)
+}
+
+func etag(hash, enc string) string {
+	if enc == "" {
+		return `"` + hash + `"`
+	}
+	return `"` + hash + "-" + enc + `"`
+}
+
+// serve writes the negotiated variant with its validators, answering conditional
+// requests that still match with 304 Not Modified and byte ranges with 206.
+func (e *encoded) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept-Encoding")
+	body, enc := e.body(r)
+	if enc == "" {
+		serveIdentity(w, r, e.hash, e.modTime, bytes.NewReader(body))
+		return
+	}
+	serveVariant(w, r, e.hash, e.modTime, body, enc)
+}
+
+// serveIdentity serves unencoded content. ServeContent answers conditional and
+// byte-range requests, including multipart ranges, against the ETag.
+func serveIdentity(w http.ResponseWriter, r *http.Request, hash string, modTime time.Time, content io.ReadSeeker) {
+	w.Header().Set("ETag", etag(hash, ""))
+	http.ServeContent(w, r, "", modTime, content)
+}
+
+// serveVariant serves a whole compressed body.
+func serveVariant(w http.ResponseWriter, r *http.Request, hash string, modTime time.Time, body []byte, enc string) {
+	h := w.Header()
+	tag := etag(hash, enc)
+	h.Set("ETag", tag)
+	if !modTime.IsZero() {
+		h.Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+	if notModified(r, tag, modTime) {
+		h.Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.Set("Content-Encoding", enc)
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+// notModified reports whether a GET or HEAD request's validators match. If-None-Match
+// takes precedence over If-Modified-Since, as in RFC 9110.
+func notModified(r *http.Request, etag string, modTime time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modTime.IsZero() {
+		return false
+	}
+	return !modTime.Truncate(time.Second).After(ims)
+}
+
+// compressible reports whether content of this type is worth compressing.
+// Media and archive formats are already compressed and are served as-is.
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch {
+	case strings.HasPrefix(mediaType, "video/"), strings.HasPrefix(mediaType, "audio/"), strings.HasPrefix(mediaType, "font/woff"):
+		return false
+	case strings.HasPrefix(mediaType, "image/"):
+		return mediaType == "image/svg+xml" || mediaType == "image/bmp" || mediaType == "image/x-icon" || mediaType == "image/vnd.microsoft.icon"
+	}
+	switch mediaType {
+	case "application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+		"application/x-bzip2", "application/x-7z-compressed", "application/x-rar-compressed",
+		"application/pdf", "application/octet-stream":
+		return false
+	}
+	return true
+}
+
+// negotiate picks the coding from available with the highest quality in the
+// Accept-Encoding header, preferring earlier entries on ties. It returns "" when
+// identity should be sent.
+func negotiate(accept string, available []string) string {
+	if accept == "" || len(available) == 0 {
+		return ""
+	}
+	q := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		if name == "*" {
+			wildcard = weight
+		} else {
+			q[name] = weight
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, enc := range available {
+		weight, ok := q[enc]
+		if !ok {
+			weight = max(wildcard, 0)
+		}
+		if weight > bestQ {
+			best, bestQ = enc, weight
+		}
+	}
+	return best
+}
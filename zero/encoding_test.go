@@ -0,0 +1,112 @@
+package zero
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	all := []string{Brotli, Zstd, Gzip}
+	tests := []struct {
+		accept    string
+		available []string
+		want      string
+	}{
+		{"", all, ""},
+		{"gzip", all, Gzip},
+		{"gzip, deflate, br", all, Brotli},
+		{"br;q=0.5, gzip", all, Gzip},
+		{"zstd, br", all, Brotli},
+		{"*", all, Brotli},
+		{"*;q=0, gzip", all, Gzip},
+		{"identity", all, ""},
+		{"br;q=0", all, ""},
+		{"br", []string{Gzip}, ""},
+	}
+	for _, tt := range tests {
+		if got := negotiate(tt.accept, tt.available); got != tt.want {
+			t.Errorf("negotiate(%q, %v) = %q, want %q", tt.accept, tt.available, got, tt.want)
+		}
+	}
+}
+
+func TestServeConditional(t *testing.T) {
+	body := encode([]byte(strings.Repeat("frame ", 64)), []string{Gzip})
+	get := func(header, value string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		body.serve(w, r)
+		return w
+	}
+
+	first := get("", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag != body.etag(Gzip) {
+		t.Fatalf("got %d with ETag %q", first.Code, etag)
+	}
+	if w := get("If-None-Match", `"other", W/`+etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("matching If-None-Match: got %d with %d bytes", w.Code, w.Body.Len())
+	}
+	if w := get("If-None-Match", body.etag("")); w.Code != http.StatusOK {
+		t.Errorf("identity ETag for gzip representation: got %d", w.Code)
+	}
+	if w := get("If-Modified-Since", first.Header().Get("Last-Modified")); w.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since: got %d", w.Code)
+	}
+}
+
+func TestServeRange(t *testing.T) {
+	body := encode([]byte("0123456789"), nil)
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Range", "bytes=2-4")
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	body.serve(w, r)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "234" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Range"); got != "bytes 2-4/10" {
+		t.Errorf("Content-Range = %q", got)
+	}
+	if got := w.Header().Get("Accept-Ranges"); got != "bytes" {
+		t.Errorf("Accept-Ranges = %q", got)
+	}
+
+	r.Header.Set("Range", "bytes=0-0,8-9")
+	w = httptest.NewRecorder()
+	body.serve(w, r)
+	if w.Code != http.StatusPartialContent || !strings.HasPrefix(w.Header().Get("Content-Type"), "multipart/byteranges") {
+		t.Fatalf("multipart: got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestEncodeLazily(t *testing.T) {
+	body := encode([]byte(strings.Repeat("frame ", 64)), []string{Brotli, Gzip})
+	if body.variants[Brotli].body != nil || body.variants[Gzip].body != nil {
+		t.Fatal("compressed before any request")
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	body.serve(w, r)
+	if got := w.Header().Get("Content-Encoding"); got != Gzip {
+		t.Fatalf("Content-Encoding = %q", got)
+	}
+	if body.variants[Brotli].body != nil {
+		t.Error("compressed a coding no request accepted")
+	}
+
+	tiny := encode([]byte("a"), []string{Brotli, Gzip})
+	r.Header.Set("Accept-Encoding", "br, gzip")
+	w = httptest.NewRecorder()
+	tiny.serve(w, r)
+	if got := w.Header().Get("Content-Encoding"); got != "" || w.Body.String() != "a" {
+		t.Fatalf("incompressible body sent with %q: %q", got, w.Body.String())
+	}
+}
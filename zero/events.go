@@ -0,0 +1,174 @@
+package zero
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event types published when the frame index or a served asset changes.
+const (
+	FrameAdded   = "frame-added"
+	FrameUpdated = "frame-updated"
+	FrameRemoved = "frame-removed"
+	FrameMoved   = "frame-moved"
+	AssetUpdated = "asset-updated"
+	AssetRemoved = "asset-removed"
+	// Resync tells a subscriber it missed events and should reload everything.
+	Resync = "resync"
+)
+
+// Event describes a change to a frame or asset. Index is -1 for asset and
+// Resync events.
+type Event struct {
+	ID    uint64 `json:"id"`
+	Type  string `json:"type"`
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+	Path  string `json:"path,omitempty"`
+	Hash  string `json:"hash,omitempty"`
+}
+
+// heartbeat is how often idle event streams receive a comment, keeping
+// proxies from timing out the connection and surfacing dead clients.
+const heartbeat = 25 * time.Second
+
+// history is how many recent events the hub keeps to replay to reconnecting
+// clients.
+const history = 256
+
+// hub fans events out to subscribers. Sends never block: a subscriber that
+// falls behind misses events, and the last slot of its buffer is kept for a
+// Resync event telling it so.
+type hub struct {
+	mu     sync.Mutex
+	seq    uint64
+	recent []Event
+	subs   map[chan Event]bool // whether a Resync is owed or queued
+}
+
+func newHub() *hub {
+	// IDs start from the clock so a client resuming with an ID from an earlier
+	// process is told to resync rather than replayed the wrong events.
+	return &hub{seq: uint64(time.Now().UnixMicro()), subs: make(map[chan Event]bool)}
+}
+
+func (h *hub) subscribe() (<-chan Event, func()) {
+	ch, cancel, _ := h.resume(nil)
+	return ch, cancel
+}
+
+// resume subscribes and returns the events published after id, or a single
+// Resync when they are no longer held. A nil id replays nothing.
+func (h *hub) resume(id *uint64) (<-chan Event, func(), []Event) {
+	ch := make(chan Event, 64)
+	h.mu.Lock()
+	h.subs[ch] = false
+	var missed []Event
+	if id != nil && *id != h.seq {
+		if n := len(h.recent); *id < h.seq && n > 0 && *id >= h.recent[0].ID-1 {
+			missed = append(missed, h.recent[n-int(h.seq-*id):]...)
+		} else {
+			missed = []Event{{ID: h.seq, Type: Resync, Index: -1}}
+		}
+	}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, ch)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}, missed
+}
+
+func (h *hub) publish(e Event) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seq++
+	e.ID = h.seq
+	if len(h.recent) == history {
+		h.recent = append(h.recent[:0], h.recent[1:]...)
+	}
+	h.recent = append(h.recent, e)
+	for ch, lagging := range h.subs {
+		switch {
+		case len(ch) < cap(ch)-1:
+			ch <- e
+			h.subs[ch] = false
+		case !lagging:
+			ch <- Event{ID: e.ID, Type: Resync, Index: -1}
+			h.subs[ch] = true
+		}
+	}
+}
+
+// HandleEvents streams frame and asset changes as Server-Sent Events. Each stream
+// opens with a "frames" event listing the current index so clients can sync. A
+// client reconnecting with Last-Event-ID is replayed what it missed, or sent a
+// Resync when that is too far back.
+func (z *zeroImpl) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	var last *uint64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		id, _ := strconv.ParseUint(v, 10, 64)
+		last = &id
+	}
+	events, cancel, missed := z.events.resume(last)
+	defer cancel()
+	// Streams are long-lived; lift any server write timeout for this response.
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	index, _ := json.Marshal(z.Index())
+	fmt.Fprintf(w, "retry: 2000\nevent: frames\ndata: %s\n\n", index)
+	for _, e := range missed {
+		writeEvent(w, e)
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w io.Writer, e Event) error {
+	data, _ := json.Marshal(e)
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
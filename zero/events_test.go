@@ -0,0 +1,154 @@
+package zero
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHubIdleSubscribers(t *testing.T) {
+	t.Parallel()
+	h := newHub()
+	var subs []<-chan Event
+	var cancels []func()
+	for range 1000 {
+		ch, cancel := h.subscribe()
+		subs = append(subs, ch)
+		cancels = append(cancels, cancel)
+	}
+	done := make(chan struct{})
+	go func() {
+		for range 200 {
+			h.publish(Event{Type: FrameUpdated})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publish blocked on idle subscribers")
+	}
+
+	for _, ch := range subs {
+		var got []Event
+		for len(ch) > 0 {
+			got = append(got, <-ch)
+		}
+		if len(got) != cap(ch) {
+			t.Fatalf("buffered %d events, want %d", len(got), cap(ch))
+		}
+		for _, e := range got[:len(got)-1] {
+			if e.Type != FrameUpdated {
+				t.Fatalf("event %+v before the resync", e)
+			}
+		}
+		if last := got[len(got)-1]; last.Type != Resync {
+			t.Fatalf("last event = %+v, want a resync", last)
+		}
+	}
+
+	h.publish(Event{Type: FrameAdded})
+	if e := <-subs[0]; e.Type != FrameAdded {
+		t.Fatalf("after draining got %+v, want frame-added", e)
+	}
+
+	for _, cancel := range cancels {
+		cancel()
+	}
+	if n := len(h.subs); n != 0 {
+		t.Fatalf("%d subscribers left after cancel", n)
+	}
+}
+
+// stream opens /events, resuming from last when set, and returns its lines.
+func stream(t *testing.T, ctx context.Context, url, last string) *bufio.Scanner {
+	t.Helper()
+	req, _ := http.NewRequestWithContext(ctx, "GET", url+"/events", nil)
+	if last != "" {
+		req.Header.Set("Last-Event-ID", last)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	return bufio.NewScanner(res.Body)
+}
+
+// next returns the type of the next event on s, skipping the opening index.
+func next(t *testing.T, s *bufio.Scanner) string {
+	t.Helper()
+	var typ string
+	for s.Scan() {
+		line := s.Text()
+		if v, ok := strings.CutPrefix(line, "event: "); ok && v != "frames" {
+			typ = v
+		}
+		if line == "" && typ != "" {
+			return typ
+		}
+	}
+	t.Fatal("stream ended")
+	return ""
+}
+
+func TestEventsResume(t *testing.T) {
+	t.Parallel()
+	z := New().(*zeroImpl)
+	srv := httptest.NewServer(z.Router())
+	t.Cleanup(srv.Close)
+
+	z.events.publish(Event{Type: FrameAdded})
+	first := strconv.FormatUint(z.events.seq, 10)
+	z.events.publish(Event{Type: FrameUpdated})
+	z.events.publish(Event{Type: FrameRemoved})
+
+	s := stream(t, t.Context(), srv.URL, first)
+	for _, want := range []string{FrameUpdated, FrameRemoved} {
+		if typ := next(t, s); typ != want {
+			t.Fatalf("replayed %q, want %q", typ, want)
+		}
+	}
+
+	for _, last := range []string{"1", "junk"} {
+		s := stream(t, t.Context(), srv.URL, last)
+		if typ := next(t, s); typ != Resync {
+			t.Fatalf("Last-Event-ID %s: got %q, want a resync", last, typ)
+		}
+	}
+}
+
+func TestEventsUnsubscribeOnDisconnect(t *testing.T) {
+	t.Parallel()
+	z := New().(*zeroImpl)
+	srv := httptest.NewServer(z.Router())
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	s := stream(t, ctx, srv.URL, "")
+	if !s.Scan() {
+		t.Fatal("stream did not open")
+	}
+	subscribers := func() int {
+		z.events.mu.Lock()
+		defer z.events.mu.Unlock()
+		return len(z.events.subs)
+	}
+	if n := subscribers(); n != 1 {
+		t.Fatalf("%d subscribers while streaming, want 1", n)
+	}
+
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for subscribers() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscriber not removed after the client disconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
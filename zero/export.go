@@ -0,0 +1,197 @@
+package zero
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Manifest describes a static export: the frame order, with the files a static
+// client loads in place of /frame requests, and every exported asset.
+type Manifest struct {
+	Frames []ExportedFrame `json:"frames"`
+	Assets []ExportedAsset `json:"assets"`
+}
+
+type ExportedFrame struct {
+	FrameInfo
+	File string `json:"file"`
+}
+
+type ExportedAsset struct {
+	Route
+	File string `json:"file"`
+}
+
+// Export writes every indexed frame, every asset route, a frames.json manifest and
+// the pathless runtime into dir so the site can be deployed on any static host. Frames are written as
+// frame/<index>.html and, when named, frame/<name>.html; assets are written at their routes with a file
+// extension, and frames link to those files in place of the API URL.
+func (z *zeroImpl) Export(dir string) error {
+	if err := os.MkdirAll(filepath.Join(dir, "frame"), 0o755); err != nil {
+		return err
+	}
+
+	var m Manifest
+	assets, err := z.ExportAssets(dir)
+	if err != nil {
+		return err
+	}
+	m.Assets = assets
+	files := make(map[string]string, len(assets))
+	for _, a := range assets {
+		files[a.Path] = a.File
+	}
+	links := regexp.MustCompile(regexp.QuoteMeta(z.ApiUrl()) + `(/[^"'\s()?#]*)`)
+
+	for _, info := range z.Index() {
+		frame := z.GetFrame(info.Index)
+		if frame == nil {
+			continue
+		}
+		html := links.ReplaceAllStringFunc(string(*frame), func(ref string) string {
+			if file, ok := files[strings.TrimPrefix(ref, z.ApiUrl())]; ok {
+				return file
+			}
+			return ref
+		})
+		file := "frame/" + strconv.Itoa(info.Index) + ".html"
+		if err := writeFile(dir, file, []byte(html)); err != nil {
+			return err
+		}
+		if info.Name != "" {
+			if err := writeFile(dir, "frame/"+info.Name+".html", []byte(html)); err != nil {
+				return err
+			}
+		}
+		m.Frames = append(m.Frames, ExportedFrame{FrameInfo: info, File: file})
+	}
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(dir, "frames.json", manifest); err != nil {
+		return err
+	}
+
+	// The runtime reads frames.json in place of /frame, and resolves apiUrl
+	// relative to the export root.
+	if err := writeFile(dir, "pathless.js", pathlessJS); err != nil {
+		return err
+	}
+	var index bytes.Buffer
+	if err := renderShell(&index, shellData{ApiUrl: ".", Runtime: "pathless.js", Manifest: "frames.json"}); err != nil {
+		return err
+	}
+	return writeFile(dir, "index.html", index.Bytes())
+}
+
+// ExportAssets writes each asset to dir at its route path plus a file extension.
+func (f *fx) ExportAssets(dir string) ([]ExportedAsset, error) {
+	routes := f.Routes()
+	exported := make([]ExportedAsset, 0, len(routes))
+	for _, route := range routes {
+		f.mu.RLock()
+		a, ok := f.assets[route.Path]
+		f.mu.RUnlock()
+		if !ok {
+			continue
+		}
+		file := exportName(route.Path, a)
+		if err := copyAsset(dir, file, a); err != nil {
+			return nil, fmt.Errorf("export %s: %w", route.Path, err)
+		}
+		exported = append(exported, ExportedAsset{Route: route, File: file})
+	}
+	return exported, nil
+}
+
+// exportName is the file an asset is exported to: its route with the source
+// file's extension, or one for its content type, so static hosts serve it with
+// the right type.
+func exportName(route string, a *asset) string {
+	name := strings.TrimPrefix(route, "/")
+	ext := filepath.Ext(a.source)
+	if ext == "" {
+		ext = typeExt(a.contentType)
+	}
+	if ext != "" && !strings.EqualFold(path.Ext(name), ext) {
+		name += ext
+	}
+	return name
+}
+
+// typeExt returns the usual file extension for a content type.
+func typeExt(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		return ".json"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/svg+xml":
+		return ".svg"
+	case "text/plain":
+		return ".txt"
+	case "text/html":
+		return ".html"
+	case "text/css":
+		return ".css"
+	case "text/javascript", "application/javascript":
+		return ".js"
+	}
+	if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// copyAsset streams the unencoded asset to the slash-separated name under dir.
+func copyAsset(dir, name string, a *asset) error {
+	src, err := a.open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	path, err := exportPath(dir, name)
+	if err != nil {
+		return err
+	}
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// writeFile writes data to the slash-separated name under dir, refusing names
+// that would escape it.
+func writeFile(dir, name string, data []byte) error {
+	path, err := exportPath(dir, name)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// exportPath resolves name under dir and creates its parent directories.
+func exportPath(dir, name string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("export: invalid path %q", name)
+	}
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	return path, os.MkdirAll(filepath.Dir(path), 0o755)
+}
@@ -2,62 +2,96 @@ package zero
 
 import (
 	"fmt"
-	"html"
 	"html/template"
 	"net/http"
 	"regexp"
-	"strconv"
 	"strings"
+	"sync"
 )
 
 type One template.HTML
 
 func NewForge() Forge {
 	f := &forge{
-		index: make([]*One, 0),
+		index:     make([]*entry, 0),
+		names:     make(map[string]int),
+		encodings: defaultEncodings,
 	}
 	return f
 }
 
 type forge struct {
-	index []*One
+	mu     sync.RWMutex
+	index  []*entry
+	names  map[string]int
+	events *hub
+	// encodings frames are compressed with when first requested.
+	encodings []string
 }
 
 type Forge interface {
-	Build(class string, updateIndex bool, elements ...*One) *One
+	Build(class string, updateIndex bool, elements ...Renderable) *One
 	JS(js string) One
 	CSS(css string) One
 	UpdateIndex(*One)
+	Register(name string, frame *One) error
+	InsertFrame(pos int, name string, frame *One) error
+	ReplaceFrame(key string, frame *One) error
+	SetMeta(key string, meta *Meta) error
+	RemoveFrame(key string) error
+	MoveFrame(key string, pos int) error
 	GetFrame(idx int) *One
+	Resolve(key string) (int, bool)
+	Index() []FrameInfo
 	Frames() int
 	Count() int
 	HandleFrame(w http.ResponseWriter, r *http.Request)
 }
 
-func (f *forge) GetFrame(idx int) *One {
-	if idx < 0 || idx >= len(f.index) {
-		return nil
-	}
-	return f.index[idx]
-}
+// Named is a Build option indexing the frame under name. Building again with
+// the same name replaces that frame in place; a name the index rejects, such as
+// a numeric one, leaves the frame addressable by position only.
+func Named(name string) Renderable { return named(name) }
+
+type named string
 
-func (f *forge) Build(class string, updateIndex bool, elements ...*One) *One {
+func (named) Render() One { return "" }
+
+// Build renders elements into a fragment. It is wrapped in a <div> when class is
+// set or Attributes are among the elements, which are applied to that wrapper.
+func (f *forge) Build(class string, updateIndex bool, elements ...Renderable) *One {
+	wrapper := El("div").Class(class)
+	wrapped := class != ""
+	name := ""
 	var b strings.Builder
 	for _, el := range elements {
-		b.WriteString(string(*el))
+		if isNil(el) {
+			continue
+		}
+		if n, ok := el.(named); ok {
+			name = string(n)
+			continue
+		}
+		if a, ok := el.(Attribute); ok {
+			a(wrapper)
+			wrapped = true
+			continue
+		}
+		b.WriteString(string(el.Render()))
 	}
 
-	var htmlOut string
-	if class == "" {
-		htmlOut = b.String()
-	} else {
-		consolidatedContent := b.String()
-		htmlOut = fmt.Sprintf(`<div class="%s">%s</div>`, html.EscapeString(class), consolidatedContent)
+	htmlOut := b.String()
+	if wrapped {
+		content := One(template.HTML(htmlOut))
+		htmlOut = string(wrapper.Append(&content).Render())
 	}
 	cleaned := f.consolidateAssets(htmlOut)
 	result := One(template.HTML(cleaned))
 
-	if updateIndex {
+	switch {
+	case updateIndex && name != "":
+		f.put(name, &result)
+	case updateIndex:
 		f.UpdateIndex(&result)
 	}
 	return &result
@@ -106,33 +140,3 @@ func (f *forge) CSS(css string) One {
 	b.WriteString(`</style>`)
 	return One(template.HTML(b.String()))
 }
-
-func (f *forge) Frames() int {
-	return int(len(f.index))
-}
-
-func (f *forge) UpdateIndex(frame *One) {
-	f.index = append(f.index, frame)
-}
-
-func (f *forge) Count() int {
-	return int(len(f.index))
-}
-
-func (f *forge) HandleFrame(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Header().Set("X-Frames", strconv.Itoa(f.Count()))
-
-	current := 0
-	if v := r.Header.Get("X-Frame"); v != "" {
-		i, err := strconv.Atoi(v)
-		if err == nil && i >= 0 && i < f.Count() {
-			current = i
-		}
-	}
-	w.Header().Set("X-Frame", strconv.Itoa(current))
-	frame := f.GetFrame(current)
-	if frame != nil {
-		fmt.Fprint(w, *frame)
-	}
-}
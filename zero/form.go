@@ -0,0 +1,380 @@
+package zero
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	csrfHeader = "X-CSRF-Token"
+	csrfField  = "_csrf"
+	csrfCookie = "frame_csrf"
+	csrfTTL    = time.Hour
+	// maxFormBytes bounds a submission's body.
+	maxFormBytes = 1 << 20
+)
+
+// Form is a submission endpoint served at /form/<Name>. GET returns a CSRF token
+// bound to a cookie it sets; POST checks both, validates Fields, then calls Handle.
+// Frame, when set, is the key of the frame the form lives in and is replaced by a
+// FormResult's Frame.
+type Form struct {
+	Name   string
+	Frame  string
+	Fields []Field
+	Handle FormHandler
+}
+
+// FormHandler handles a validated submission. Returning FieldErrors reports them
+// to the client like failed validation; other errors are logged and hidden.
+type FormHandler func(r *http.Request, values url.Values) (*FormResult, error)
+
+// FormResult is sent back to the client. Data is returned as JSON and defaults
+// to nothing. Frame replaces the owning frame for every client, not just the
+// submitter, so it suits shared state such as a guestbook; results for the
+// submitter alone belong in Message or Data.
+type FormResult struct {
+	Message string
+	Data    any
+	Frame   *One
+}
+
+// Field validates one submitted value.
+type Field struct {
+	Name     string
+	Required bool
+	// MaxLen limits the value's length in characters; 0 means no limit.
+	MaxLen  int
+	Pattern *regexp.Regexp
+	Check   func(value string) error
+}
+
+// FieldError is a validation failure of one field.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string { return e.Field + ": " + e.Message }
+
+// validate returns the first failure of each field.
+func (fl Field) validate(values url.Values) *FieldError {
+	v := strings.TrimSpace(values.Get(fl.Name))
+	switch {
+	case v == "" && fl.Required:
+		return &FieldError{fl.Name, "is required"}
+	case v == "":
+		return nil
+	case fl.MaxLen > 0 && len([]rune(v)) > fl.MaxLen:
+		return &FieldError{fl.Name, fmt.Sprintf("must be at most %d characters", fl.MaxLen)}
+	case fl.Pattern != nil && !fl.Pattern.MatchString(v):
+		return &FieldError{fl.Name, "is not valid"}
+	}
+	if fl.Check != nil {
+		if err := fl.Check(v); err != nil {
+			return &FieldError{fl.Name, err.Error()}
+		}
+	}
+	return nil
+}
+
+// forms serves registered forms. Tokens are stateless: an expiry and an HMAC of it,
+// the form name and the client's cookie under a per-process secret, so a token is
+// only good alongside the cookie it was issued with.
+type forms struct {
+	mu      sync.RWMutex
+	byName  map[string]*Form
+	secret  []byte
+	secure  bool
+	origins map[string]bool
+	forge   Forge
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+func newForms(c *config, forge Forge) *forms {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	origins := map[string]bool{origin(c.apiURL): true}
+	for _, o := range c.origins {
+		origins[strings.TrimSuffix(o, "/")] = true
+	}
+	return &forms{
+		byName:  make(map[string]*Form),
+		secret:  secret,
+		secure:  strings.HasPrefix(c.apiURL, "https://"),
+		origins: origins,
+		forge:   forge,
+		logger:  c.logger,
+		now:     time.Now,
+	}
+}
+
+// AddForm registers form and returns its action URL.
+func (z *zeroImpl) AddForm(form Form) (string, error) {
+	name, err := frameName(form.Name)
+	if err != nil {
+		return "", fmt.Errorf("form: %w", err)
+	}
+	if form.Handle == nil {
+		return "", fmt.Errorf("form %q: no handler", name)
+	}
+	form.Name = name
+	z.forms.mu.Lock()
+	z.forms.byName[name] = &form
+	z.forms.mu.Unlock()
+	return z.ApiUrl() + "/form/" + url.PathEscape(name), nil
+}
+
+func (fs *forms) get(name string) *Form {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.byName[strings.ToLower(name)]
+}
+
+func (fs *forms) token(name, client string, expires time.Time) string {
+	buf := binary.BigEndian.AppendUint64(nil, uint64(expires.Unix()))
+	buf = append(buf, fs.sign(name, client, buf)...)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func (fs *forms) sign(name, client string, expiry []byte) []byte {
+	mac := hmac.New(sha256.New, fs.secret)
+	mac.Write(expiry)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write([]byte(client))
+	return mac.Sum(nil)[:16]
+}
+
+func (fs *forms) verify(name, client, token string) bool {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(buf) != 24 || client == "" {
+		return false
+	}
+	expiry := buf[:8]
+	if fs.now().Unix() > int64(binary.BigEndian.Uint64(expiry)) {
+		return false
+	}
+	return hmac.Equal(buf[8:], fs.sign(name, client, expiry))
+}
+
+// client returns the caller's CSRF cookie, issuing one when it has none. The
+// pathless client usually runs on another origin, so over HTTPS the cookie is
+// sent cross-site.
+func (fs *forms) client(w http.ResponseWriter, r *http.Request) string {
+	if c, err := r.Cookie(csrfCookie); err == nil && c.Value != "" {
+		return c.Value
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	value := base64.RawURLEncoding.EncodeToString(id)
+	cookie := &http.Cookie{Name: csrfCookie, Value: value, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode}
+	if fs.secure {
+		cookie.Secure, cookie.SameSite = true, http.SameSiteNoneMode
+	}
+	http.SetCookie(w, cookie)
+	return value
+}
+
+// submitter returns the value of the caller's CSRF cookie, "" when it has none.
+func submitter(r *http.Request) string {
+	c, err := r.Cookie(csrfCookie)
+	if err != nil {
+		return ""
+	}
+	return c.Value
+}
+
+// allowed accepts submissions from the API's own origin and the configured ones,
+// taken from Origin or, failing that, Referer. Requests carrying neither are
+// rejected.
+func (fs *forms) allowed(r *http.Request) bool {
+	o := r.Header.Get("Origin")
+	if o == "" {
+		if ref := r.Header.Get("Referer"); ref != "" {
+			o = origin(ref)
+		}
+	}
+	return o != "" && (fs.origins["*"] || fs.origins[o])
+}
+
+// ServeHTTP answers GET with a fresh token and POST with the submission's result.
+func (fs *forms) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	form := fs.get(mux.Vars(r)["name"])
+	if form == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	switch r.Method {
+	case http.MethodGet:
+		expires := fs.now().Add(csrfTTL)
+		token := fs.token(form.Name, fs.client(w, r), expires)
+		writeJSON(w, http.StatusOK, map[string]any{"token": token, "expires": expires.UTC()})
+	case http.MethodPost:
+		fs.submit(w, r, form)
+	}
+}
+
+func (fs *forms) submit(w http.ResponseWriter, r *http.Request, form *Form) {
+	if !fs.allowed(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxFormBytes)
+	var err error
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+		err = r.ParseMultipartForm(maxFormBytes)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	values := r.PostForm
+	token := r.Header.Get(csrfHeader)
+	if token == "" {
+		token = values.Get(csrfField)
+	}
+	if !fs.verify(form.Name, submitter(r), token) {
+		respond(w, r, http.StatusForbidden, formResponse{Message: "form expired, please submit again"})
+		return
+	}
+	values.Del(csrfField)
+
+	var invalid []*FieldError
+	for _, fl := range form.Fields {
+		if fe := fl.validate(values); fe != nil {
+			invalid = append(invalid, fe)
+		}
+	}
+	if len(invalid) > 0 {
+		respond(w, r, http.StatusUnprocessableEntity, failed(invalid))
+		return
+	}
+
+	result, err := form.Handle(r, values)
+	if err != nil {
+		var fe *FieldError
+		if errors.As(err, &fe) {
+			respond(w, r, http.StatusUnprocessableEntity, failed(fieldErrors(err)))
+			return
+		}
+		fs.logger.Error("form handler failed", "form", form.Name, "err", err)
+		respond(w, r, http.StatusInternalServerError, formResponse{Message: "something went wrong"})
+		return
+	}
+	if result == nil {
+		result = &FormResult{}
+	}
+	resp := formResponse{OK: true, Message: result.Message, Data: result.Data}
+	if result.Frame != nil && form.Frame != "" {
+		if err := fs.forge.ReplaceFrame(form.Frame, result.Frame); err != nil {
+			fs.logger.Warn("form frame not replaced", "form", form.Name, "frame", form.Frame, "err", err)
+		} else {
+			resp.Frame = form.Frame
+		}
+	}
+	respond(w, r, http.StatusOK, resp)
+}
+
+// fieldErrors collects every FieldError in err, including joined ones.
+func fieldErrors(err error) []*FieldError {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var out []*FieldError
+		for _, e := range joined.Unwrap() {
+			out = append(out, fieldErrors(e)...)
+		}
+		return out
+	}
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		return []*FieldError{fe}
+	}
+	return nil
+}
+
+type formResponse struct {
+	OK      bool              `json:"ok"`
+	Message string            `json:"message,omitempty"`
+	Errors  map[string]string `json:"errors,omitempty"`
+	Data    any               `json:"data,omitempty"`
+	Frame   string            `json:"frame,omitempty"`
+}
+
+func failed(invalid []*FieldError) formResponse {
+	resp := formResponse{Errors: make(map[string]string, len(invalid))}
+	for _, fe := range invalid {
+		resp.Errors[fe.Field] = fe.Message
+	}
+	return resp
+}
+
+// respond writes JSON, or an HTML fragment when the client prefers text/html.
+func respond(w http.ResponseWriter, r *http.Request, status int, resp formResponse) {
+	if !prefersHTML(r.Header.Get("Accept")) {
+		writeJSON(w, status, resp)
+		return
+	}
+	out := El("div").Class("form-result").Attr("role", "status")
+	if !resp.OK {
+		out.Class("error")
+	}
+	out.Text(resp.Message)
+	if len(resp.Errors) > 0 {
+		list := El("ul")
+		for _, field := range slices.Sorted(maps.Keys(resp.Errors)) {
+			list.Append(Tag("li", field+" "+resp.Errors[field]).Data("field", field))
+		}
+		out.Append(list)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write([]byte(out.Render()))
+}
+
+// prefersHTML reports whether text/html comes before any JSON type in accept.
+func prefersHTML(accept string) bool {
+	h, j := strings.Index(accept, "text/html"), strings.Index(accept, "json")
+	return h >= 0 && (j < 0 || h < j)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// origin is the scheme and host of rawURL.
+func origin(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
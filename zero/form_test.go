@@ -0,0 +1,126 @@
+package zero
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFormSubmit(t *testing.T) {
+	z := New(WithOrigins("https://site.test"))
+	if err := z.Register("contact", frame("<p>before</p>")); err != nil {
+		t.Fatal(err)
+	}
+	action, err := z.AddForm(Form{
+		Name:  "contact",
+		Frame: "contact",
+		Fields: []Field{
+			{Name: "email", Required: true, Pattern: regexp.MustCompile(`^[^@]+@[^@]+$`)},
+			{Name: "message", MaxLen: 5},
+		},
+		Handle: func(r *http.Request, v url.Values) (*FormResult, error) {
+			if v.Get("email") == "taken@x" {
+				return nil, &FieldError{"email", "is taken"}
+			}
+			if v.Get("email") == "boom@x" {
+				return nil, errors.New("boom")
+			}
+			return &FormResult{Message: "thanks", Frame: frame("<p>after</p>")}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := strings.TrimPrefix(action, z.ApiUrl())
+
+	rec := httptest.NewRecorder()
+	z.Router().ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+	var tok struct{ Token string }
+	if err := json.Unmarshal(rec.Body.Bytes(), &tok); err != nil || tok.Token == "" {
+		t.Fatalf("token response %q: %v", rec.Body, err)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != csrfCookie || !cookies[0].HttpOnly {
+		t.Fatalf("csrf cookie = %v", cookies)
+	}
+	mine, theirs := cookies[0], &http.Cookie{Name: csrfCookie, Value: "someone-else"}
+
+	const site = "https://site.test"
+	post := func(form url.Values, token string, cookie *http.Cookie, origin, referer, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if token != "" {
+			req.Header.Set(csrfHeader, token)
+		}
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if referer != "" {
+			req.Header.Set("Referer", referer)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		z.Router().ServeHTTP(rec, req)
+		return rec
+	}
+	valid := url.Values{"email": {"a@b"}, "message": {"hi"}}
+
+	cases := []struct {
+		name   string
+		rec    *httptest.ResponseRecorder
+		status int
+		body   string
+	}{
+		{"no token", post(valid, "", mine, site, "", ""), 403, `"ok":false`},
+		{"bad token", post(valid, tok.Token+"x", mine, site, "", ""), 403, `expired`},
+		{"no cookie", post(valid, tok.Token, nil, site, "", ""), 403, `expired`},
+		{"other cookie", post(valid, tok.Token, theirs, site, "", ""), 403, `expired`},
+		{"foreign origin", post(valid, tok.Token, mine, "https://evil.test", "", ""), 403, `origin`},
+		{"foreign referer", post(valid, tok.Token, mine, "", "https://evil.test/page", ""), 403, `origin`},
+		{"no origin", post(valid, tok.Token, mine, "", "", ""), 403, `origin`},
+		{"invalid", post(url.Values{"message": {"too long"}}, tok.Token, mine, site, "", ""), 422, `"errors":{"email":"is required","message":"must be at most 5 characters"}`},
+		{"handler field error", post(url.Values{"email": {"taken@x"}}, tok.Token, mine, site, "", ""), 422, `"email":"is taken"`},
+		{"handler error", post(url.Values{"email": {"boom@x"}}, tok.Token, mine, "", site+"/page", ""), 500, `something went wrong`},
+		{"html", post(url.Values{"email": {"nope"}}, tok.Token, mine, site, "", "text/html"), 422, `<li data-field="email">email is not valid</li>`},
+		{"ok", post(valid, tok.Token, mine, site, "", ""), 200, `{"ok":true,"message":"thanks","frame":"contact"}`},
+	}
+	for _, c := range cases {
+		if c.rec.Code != c.status || !strings.Contains(c.rec.Body.String(), c.body) {
+			t.Errorf("%s: %d %s, want %d containing %s", c.name, c.rec.Code, c.rec.Body, c.status, c.body)
+		}
+	}
+
+	i, _ := z.Resolve("contact")
+	if got := string(*z.GetFrame(i)); got != "<p>after</p>" {
+		t.Errorf("frame not re-rendered: %s", got)
+	}
+}
+
+func TestFormElements(t *testing.T) {
+	e := NewElement()
+	form := e.Form("/form/contact",
+		e.Fieldset("Contact",
+			e.Label("email", "Email"),
+			e.Input("email", "email", Required()),
+			e.Select("topic", []string{"a", "b"}, Value("b")),
+			e.Textarea("message", "<hi>"),
+		),
+	)
+	want := `<form method="post" action="/form/contact" data-form><fieldset><legend>Contact</legend>` +
+		`<label for="email">Email</label><input type="email" name="email" id="email" required>` +
+		`<select name="topic" id="topic"><option value="a">a</option><option value="b" selected>b</option></select>` +
+		`<textarea name="message" id="message">&lt;hi&gt;</textarea></fieldset></form>`
+	if got := string(form.Render()); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
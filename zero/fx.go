@@ -2,12 +2,23 @@ package zero
 
 import (
 	"bytes"
-	"compress/gzip"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"mime"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
@@ -15,10 +26,18 @@ import (
 
 type Fx interface {
 	AddFile(filePath string, prefix string) error
-	AddPath(dir string) string
+	AddPath(dir string, opts ...PathOption) ([]string, error)
+	AddFS(fsys fs.FS, prefix string, opts ...PathOption) ([]string, error)
+	AddContent(route, contentType string, data []byte)
+	Routes() []Route
+	ExportAssets(dir string) ([]ExportedAsset, error)
+	Track(path string, reload func())
+	Watch(ctx context.Context, interval time.Duration) error
 	PathlessUrl() string
 	ApiUrl() string
-	Serve()
+	Logger() *slog.Logger
+	CacheControl(prefix, policy string)
+	Serve(ctx context.Context, opts ...ServeOption) error
 	Router() *mux.Router
 }
 
@@ -26,21 +45,86 @@ type fx struct {
 	router      *mux.Router
 	pathlessUrl string
 	apiURL      string
+	addr        string
+	logger      *slog.Logger
+	encodings   []string
+	collisions  CollisionPolicy
+	fsCount     int
+	threshold   int64
+	variants    *lru
+	mu          sync.RWMutex
+	assets      map[string]*asset
+	cache       map[string]string
+	events      *hub
+	watch       *watcher
 }
 
-func NewFx(pathlessUrl, apiUrl string) Fx {
-	if apiUrl == "" {
-		apiUrl = "http://localhost:1001"
-	} else if !strings.HasPrefix(apiUrl, "http://") && !strings.HasPrefix(apiUrl, "https://") {
-		apiUrl = "https://" + apiUrl
+// asset is a file served at a fixed route, from memory or, above the disk
+// threshold, from disk.
+type asset struct {
+	source      string
+	contentType string
+	body        *encoded
+	file        *diskFile
+}
+
+// Route describes a registered asset route.
+type Route struct {
+	Path        string `json:"path"`
+	ContentType string `json:"type"`
+	Size        int64  `json:"size"`
+	Hash        string `json:"hash"`
+	Source      string `json:"source,omitempty"`
+}
+
+func (a *asset) size() int64 {
+	if a.file != nil {
+		_, size, _ := a.file.validators()
+		return size
+	}
+	return int64(len(a.body.identity))
+}
+
+func (a *asset) hash() string {
+	if a.file != nil {
+		hash, _, _ := a.file.validators()
+		return hash
+	}
+	return a.body.hash
+}
+
+// open returns the unencoded content of the asset.
+func (a *asset) open() (io.ReadCloser, error) {
+	if a.file != nil {
+		return a.file.fsys.Open(a.file.name)
 	}
+	return io.NopCloser(bytes.NewReader(a.body.identity)), nil
+}
+
+func NewFx(pathlessUrl, apiUrl string) Fx {
+	return newFx(newConfig(WithPathlessURL(pathlessUrl), WithAPIURL(apiUrl)))
+}
 
+func newFx(c *config) *fx {
 	f := &fx{
 		router:      mux.NewRouter(),
-		pathlessUrl: pathlessUrl,
-		apiURL:      apiUrl,
+		pathlessUrl: c.pathlessUrl,
+		apiURL:      c.apiURL,
+		addr:        c.addr,
+		logger:      c.logger,
+		encodings:   c.encodings,
+		collisions:  c.collisions,
+		threshold:   c.diskThreshold,
+		variants:    newLRU(c.cacheSize),
+		assets:      make(map[string]*asset),
+		cache:       maps.Clone(c.cache),
+		watch:       newWatcher(c.logger),
 	}
-	f.router.Use(f.cors(pathlessUrl))
+	f.router.Use(f.cors(c), f.cacheControl)
+	// Assets are looked up per request rather than registered as individual routes,
+	// so they can be replaced or removed while the router is serving.
+	f.router.MatcherFunc(f.hasAsset).HandlerFunc(f.serveAsset)
+	f.handleRuntime()
 	return f
 }
 
@@ -56,64 +140,245 @@ func (f *fx) ApiUrl() string {
 	return f.apiURL
 }
 
-func (f *fx) Serve() {
-	go func() {
-		http.ListenAndServe(":1001", f.Router())
-	}()
+func (f *fx) Logger() *slog.Logger {
+	return f.logger
 }
 
-func (f *fx) cors(pathlessUrl string) mux.MiddlewareFunc {
-	origin := "http://localhost:1000"
-	if pathlessUrl != "" {
-		origin = "https://" + pathlessUrl
-	}
+// CacheControl sets the Cache-Control policy for routes under prefix.
+func (f *fx) CacheControl(prefix, policy string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cache[prefix] = policy
+}
+
+// cacheControl applies the policy of the longest prefix matching the request path.
+// Handlers may still override it.
+func (f *fx) cacheControl(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.RLock()
+		best, policy := -1, ""
+		for prefix, p := range f.cache {
+			if len(prefix) > best && underPrefix(r.URL.Path, prefix) {
+				best, policy = len(prefix), p
+			}
+		}
+		f.mu.RUnlock()
+		if policy != "" {
+			w.Header().Set("Cache-Control", policy)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// underPrefix reports whether path is prefix or lies beneath it, on segment boundaries.
+func underPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
 
+func (f *fx) cors(c *config) mux.MiddlewareFunc {
 	return handlers.CORS(
-		handlers.AllowedHeaders([]string{"Content-Type", "X-Frame"}),
-		handlers.AllowedOrigins([]string{origin}),
-		handlers.AllowedMethods([]string{"GET", "OPTIONS"}),
-		handlers.ExposedHeaders([]string{"X-Frame", "X-Frames"}),
+		handlers.AllowedHeaders(c.headers),
+		handlers.AllowedOrigins(c.origins),
+		handlers.AllowedMethods(c.methods),
+		handlers.ExposedHeaders([]string{"X-Frame", "X-Frames", "X-Frame-Name"}),
+		// Form tokens are bound to a cookie the pathless client must send along.
+		handlers.AllowCredentials(),
 	)
 }
 
 // Add a single file to the frame with a prefix path
 func (f *fx) AddFile(filePath string, prefix string) error {
-	fileData, err := os.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
 	base := filepath.Base(filePath)
 	name := base[:len(base)-len(filepath.Ext(base))]
-	contentType := f.getType(base, fileData)
 	routePath := "/" + strings.Trim(prefix, "/") + "/" + name
+	fsys := os.DirFS(filepath.Dir(filePath))
 
-	f.addRoute(routePath, fileData, contentType)
+	if owner := f.owner(routePath); owner != "" && owner != filePath {
+		keep, err := f.collisions(routePath, owner, filePath)
+		if keep == owner {
+			return err
+		}
+	}
+	if err := f.loadFile(fsys, base, filePath, routePath); err != nil {
+		return err
+	}
+	f.Track(filePath, func() {
+		if err := f.loadFile(fsys, base, filePath, routePath); err != nil {
+			f.removeRoute(routePath)
+		}
+	})
 	return nil
 }
 
-// Walk directory and load files into memory, determine Content-Type based on file extension, register routes as /<dirname>/<file without extension>
-func (f *fx) AddPath(dir string) string {
+// PathOption selects the files AddPath and AddFS serve.
+type PathOption func(*pathConfig)
+
+type pathConfig struct {
+	skipExts []string
+}
+
+// SkipExt leaves files with any of the extensions exts unserved, such as the
+// markdown sources of pages rendered into frames.
+func SkipExt(exts ...string) PathOption {
+	return func(c *pathConfig) { c.skipExts = append(c.skipExts, exts...) }
+}
+
+func (c *pathConfig) skip(name string) bool {
+	ext := path.Ext(name)
+	return slices.ContainsFunc(c.skipExts, func(e string) bool { return strings.EqualFold(e, ext) })
+}
+
+func newPathConfig(opts []PathOption) *pathConfig {
+	c := &pathConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// AddPath walks dir and serves every file under /<dirname>/<relative path without extension>,
+// with Content-Type from the file extension. It returns the registered routes; files
+// whose routes collide are resolved by the collision policy and reported in the error.
+// In watch mode files added, changed or removed under dir are picked up.
+func (f *fx) AddPath(dir string, opts ...PathOption) ([]string, error) {
 	prefix := filepath.Base(dir)
-	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil || info.IsDir() {
-			return err
+	fsys := os.DirFS(dir)
+	c := newPathConfig(opts)
+	routes, err := f.loadFS(fsys, prefix, dir, c)
+	f.Track(dir, func() {
+		current, err := f.loadFS(fsys, prefix, dir, c)
+		if err != nil {
+			f.logger.Warn("reloading path", "dir", dir, "err", err)
+		}
+		for _, route := range routes {
+			if !slices.Contains(current, route) {
+				f.removeRoute(route)
+			}
 		}
+		routes = current
+	})
+	return slices.Clone(routes), err
+}
+
+// AddFS serves every file in fsys under /<prefix>/<path without extension>, like AddPath,
+// e.g. an embed.FS for single-binary deployments. Files are not watched for changes.
+func (f *fx) AddFS(fsys fs.FS, prefix string, opts ...PathOption) ([]string, error) {
+	f.mu.Lock()
+	f.fsCount++
+	origin := "fs" + strconv.Itoa(f.fsCount) + ":" + strings.Trim(prefix, "/")
+	f.mu.Unlock()
+	return f.loadFS(fsys, strings.Trim(prefix, "/"), origin, newPathConfig(opts))
+}
 
-		fileData, err := os.ReadFile(path)
+// loadFS serves the files of fsys under prefix. Sources are recorded as paths
+// under origin, which identifies fsys when resolving collisions.
+func (f *fx) loadFS(fsys fs.FS, prefix, origin string, c *pathConfig) ([]string, error) {
+	var errs []error
+	claims := make(map[string]string)
+	fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
 		if err != nil {
-			return err
+			errs = append(errs, err)
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") && name != "." {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || c.skip(name) {
+			return nil
 		}
 
-		base := filepath.Base(path)
-		name := base[:len(base)-len(filepath.Ext(base))]
-		contentType := f.getType(base, fileData)
-		routePath := "/" + prefix + "/" + name
+		route := "/" + prefix + "/" + strings.TrimSuffix(name, path.Ext(name))
+		source := filepath.Join(origin, filepath.FromSlash(name))
 
-		f.addRoute(routePath, fileData, contentType)
+		existing, claimed := claims[route]
+		if !claimed {
+			// Routes served from this origin before a reload are not collisions.
+			if owner := f.owner(route); owner != "" && !within(owner, origin) {
+				existing, claimed = owner, true
+			}
+		}
+		if claimed {
+			keep, err := f.collisions(route, existing, source)
+			errs = append(errs, err)
+			if keep == existing {
+				return nil
+			}
+		}
+		claims[route] = name
 		return nil
 	})
-	return prefix
+
+	routes := make([]string, 0, len(claims))
+	for _, route := range slices.Sorted(maps.Keys(claims)) {
+		name := claims[route]
+		if err := f.loadFile(fsys, name, filepath.Join(origin, filepath.FromSlash(name)), route); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		routes = append(routes, route)
+	}
+	return routes, errors.Join(errs...)
+}
+
+// AddContent serves data generated in memory at route, replacing any asset there.
+func (f *fx) AddContent(route, contentType string, data []byte) {
+	f.addRoute(route, "", data, contentType, time.Now())
+}
+
+// Routes lists the registered asset routes in path order.
+func (f *fx) Routes() []Route {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	routes := make([]Route, 0, len(f.assets))
+	for _, path := range slices.Sorted(maps.Keys(f.assets)) {
+		a := f.assets[path]
+		routes = append(routes, Route{
+			Path:        path,
+			ContentType: a.contentType,
+			Size:        a.size(),
+			Hash:        a.hash(),
+			Source:      a.source,
+		})
+	}
+	return routes
+}
+
+// owner returns the source file serving route, if any.
+func (f *fx) owner(route string) string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if a, ok := f.assets[route]; ok {
+		return a.source
+	}
+	return ""
+}
+
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// loadFile reads name from fsys and serves it at routePath, last modified at the file's mtime.
+func (f *fx) loadFile(fsys fs.FS, name, source, routePath string) error {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return err
+	}
+	if f.threshold > 0 && info.Size() > f.threshold {
+		if ok, err := f.addDiskRoute(routePath, fsys, name, source, info); ok || err != nil {
+			return err
+		}
+	}
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	f.addRoute(routePath, source, data, f.getType(path.Base(name), data), info.ModTime())
+	return nil
 }
 
 func (f *fx) getType(filename string, data []byte) string {
@@ -124,16 +389,96 @@ func (f *fx) getType(filename string, data []byte) string {
 	return contentType
 }
 
-func (f *fx) addRoute(path string, data []byte, contentType string) {
-	var buf bytes.Buffer
-	gzipWriter := gzip.NewWriter(&buf)
-	gzipWriter.Write(data)
-	gzipWriter.Close()
-	zipped := buf.Bytes()
+func (f *fx) addRoute(path, source string, data []byte, contentType string, modTime time.Time) {
+	a := &asset{source: source, contentType: contentType, body: encode(data, f.encodingsFor(contentType))}
+	a.body.modTime = modTime
+	f.setAsset(path, a)
+}
 
-	f.Router().HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Encoding", "gzip")
-		w.Header().Set("Content-Type", contentType)
-		w.Write(zipped)
-	})
+// addDiskRoute serves name from fsys on each request rather than from memory. It
+// reports false if the file cannot seek, in which case it must be held in memory.
+func (f *fx) addDiskRoute(route string, fsys fs.FS, name, source string, info fs.FileInfo) (bool, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return false, err
+	}
+	_, seekable := file.(io.ReadSeeker)
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	file.Close()
+	if !seekable {
+		return false, nil
+	}
+
+	contentType := f.getType(path.Base(name), head[:n])
+	d, err := newDiskFile(fsys, name, info, f.encodingsFor(contentType))
+	if err != nil {
+		return false, err
+	}
+	f.setAsset(route, &asset{source: source, contentType: contentType, file: d})
+	return true, nil
+}
+
+func (f *fx) encodingsFor(contentType string) []string {
+	if !compressible(contentType) {
+		return nil
+	}
+	return f.encodings
+}
+
+func (f *fx) setAsset(path string, a *asset) {
+	f.mu.Lock()
+	f.assets[path] = a
+	f.mu.Unlock()
+	f.events.publish(Event{Type: AssetUpdated, Index: -1, Path: path, Hash: a.hash()})
+}
+
+func (f *fx) removeRoute(path string) {
+	f.mu.Lock()
+	_, ok := f.assets[path]
+	delete(f.assets, path)
+	f.mu.Unlock()
+	if ok {
+		f.events.publish(Event{Type: AssetRemoved, Index: -1, Path: path})
+	}
+}
+
+func (f *fx) hasAsset(r *http.Request, _ *mux.RouteMatch) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	_, ok := f.assets[r.URL.Path]
+	return ok
+}
+
+// image reports whether route is served and, when it is a raster image, its dimensions.
+func (f *fx) image(route string) (image.Config, bool) {
+	f.mu.RLock()
+	a, ok := f.assets[route]
+	f.mu.RUnlock()
+	if !ok || !strings.HasPrefix(a.contentType, "image/") {
+		return image.Config{}, ok
+	}
+	r, err := a.open()
+	if err != nil {
+		return image.Config{}, true
+	}
+	defer r.Close()
+	cfg, _, _ := image.DecodeConfig(r)
+	return cfg, true
+}
+
+func (f *fx) serveAsset(w http.ResponseWriter, r *http.Request) {
+	f.mu.RLock()
+	a, ok := f.assets[r.URL.Path]
+	f.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", a.contentType)
+	if a.file != nil {
+		a.file.serve(w, r, f.variants)
+		return
+	}
+	a.body.serve(w, r)
 }
@@ -0,0 +1,108 @@
+package zero
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func writeTree(t *testing.T, root string, files ...string) {
+	t.Helper()
+	for _, name := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAddPathNestedRoutes(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "img")
+	writeTree(t, dir, "a/logo.png", "b/logo.svg", "top.txt", ".DS_Store")
+
+	routes, err := NewZero("", "").AddPath(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/img/a/logo", "/img/b/logo", "/img/top"}
+	if !slices.Equal(routes, want) {
+		t.Errorf("routes = %v, want %v", routes, want)
+	}
+}
+
+func TestAddPathCollisions(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "img")
+	writeTree(t, dir, "logo.png", "logo.webp")
+
+	tests := []struct {
+		name    string
+		policy  CollisionPolicy
+		keep    string
+		wantErr bool
+	}{
+		{"error", CollisionError, "logo.png", true},
+		{"first wins", FirstWins, "logo.png", false},
+		{"prefer", PreferExt(".webp", ".png"), "logo.webp", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := New(WithCollisionPolicy(tt.policy))
+			routes, err := z.AddPath(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !slices.Equal(routes, []string{"/img/logo"}) {
+				t.Fatalf("routes = %v", routes)
+			}
+			if got := filepath.Base(z.(*zeroImpl).Fx.(*fx).owner("/img/logo")); got != tt.keep {
+				t.Errorf("kept %s, want %s", got, tt.keep)
+			}
+		})
+	}
+
+	// A second directory with the same base name collides with the first.
+	other := filepath.Join(t.TempDir(), "img")
+	writeTree(t, other, "logo.gif")
+	z := NewZero("", "")
+	z.AddPath(dir)
+	if _, err := z.AddPath(other); err == nil {
+		t.Error("expected a collision across directories")
+	}
+}
+
+func TestAddFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"logo.svg":        {Data: []byte("<svg></svg>")},
+		"slides/one.txt":  {Data: []byte(strings.Repeat("one ", 64))},
+		".hidden/ignored": {Data: []byte("x")},
+	}
+	z := New(WithDiskThreshold(100))
+	routes, err := z.AddFS(fsys, "/assets/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/assets/logo", "/assets/slides/one"}; !slices.Equal(routes, want) {
+		t.Fatalf("routes = %v, want %v", routes, want)
+	}
+
+	for route, want := range map[string]string{
+		"/assets/logo":       "<svg></svg>",
+		"/assets/slides/one": strings.Repeat("one ", 64),
+	} {
+		w := httptest.NewRecorder()
+		z.Router().ServeHTTP(w, httptest.NewRequest("GET", route, nil))
+		if w.Code != http.StatusOK || w.Body.String() != want {
+			t.Errorf("%s: got %d %q", route, w.Code, w.Body.String())
+		}
+	}
+
+	if _, err := z.AddFS(fstest.MapFS{"logo.png": {Data: []byte("png")}}, "assets"); err == nil {
+		t.Error("expected a collision with the first file system")
+	}
+}
@@ -0,0 +1,103 @@
+package zero
+
+import (
+	"bytes"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+)
+
+// DefaultHighlightStyle is the chroma style WithHighlighting uses when given none.
+const DefaultHighlightStyle = "github"
+
+// highlighter renders code server-side as chroma class-based HTML, so a frame
+// needs only the style's CSS from HighlightCSS.
+type highlighter struct {
+	style       string
+	lineNumbers bool
+}
+
+func newHighlighter(c *config) *highlighter {
+	if c.highlightStyle == "" {
+		return nil
+	}
+	return &highlighter{style: c.highlightStyle, lineNumbers: c.lineNumbers}
+}
+
+func (h *highlighter) options(numbers bool, lines [][2]int) []chromahtml.Option {
+	return []chromahtml.Option{
+		chromahtml.WithClasses(true),
+		chromahtml.WithLineNumbers(numbers),
+		chromahtml.HighlightLines(lines),
+	}
+}
+
+// extension highlights fenced code in markdown. Fences take the same options
+// as CodeBlock through attributes: ```go {hl_lines=["2-3"] linenos=true}
+func (h *highlighter) extension() goldmark.Extender {
+	return highlighting.NewHighlighting(
+		highlighting.WithStyle(h.style),
+		highlighting.WithFormatOptions(h.options(h.lineNumbers, nil)...),
+	)
+}
+
+// highlight renders code in lang, guessing the language when lang is unknown.
+func (h *highlighter) highlight(lang, code string, numbers bool, lines [][2]int) (One, error) {
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	tokens, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := chromahtml.New(h.options(numbers, lines)...).Format(&b, styles.Get(h.style), tokens); err != nil {
+		return "", err
+	}
+	return One(template.HTML(b.String())), nil
+}
+
+// HighlightCSS returns the class-based CSS of a chroma style, or of the
+// fallback style when name is unknown.
+func HighlightCSS(name string) string {
+	var b bytes.Buffer
+	chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&b, styles.Get(name))
+	return b.String()
+}
+
+// LineNumbers numbers the lines of a CodeBlock.
+func LineNumbers() Attribute { return Bool("data-linenos") }
+
+// HighlightLines marks line ranges of a CodeBlock, written like "2,4-6".
+func HighlightLines(ranges string) Attribute { return A("data-hl-lines", ranges) }
+
+// parseLines reads "2,4-6" into chroma's inclusive ranges, skipping malformed parts.
+func parseLines(s string) [][2]int {
+	var out [][2]int
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		start, err := strconv.Atoi(from)
+		if err != nil {
+			continue
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(to); err != nil {
+				continue
+			}
+		}
+		out = append(out, [2]int{start, end})
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package zero
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCodeBlockHighlight(t *testing.T) {
+	if got := string(NewElement().CodeBlock("go", "var x").Render()); strings.Contains(got, "chroma") {
+		t.Errorf("highlighted without WithHighlighting: %s", got)
+	}
+
+	e := newElement(newConfig(WithHighlighting("")))
+	got := string(e.CodeBlock("go", "package main\n\nfunc main() {}\n", HighlightLines("3"), LineNumbers(), ID("c")).Render())
+	for _, want := range []string{`<div class="highlight" id="c"><pre class="chroma">`, `<span class="kn">package</span>`, `<span class="line hl">`, `<span class="ln">3</span>`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %s in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "data-") || strings.Contains(got, "style=") {
+		t.Errorf("options or inline styles leaked: %s", got)
+	}
+
+	var b bytes.Buffer
+	if err := (*e.Markdown()).Convert([]byte("```go {hl_lines=[\"1\"]}\nvar x = 1\n```\n"), &b); err != nil {
+		t.Fatal(err)
+	}
+	if md := b.String(); !strings.Contains(md, `<pre class="chroma">`) || !strings.Contains(md, `class="line hl"`) {
+		t.Errorf("markdown fence not highlighted:\n%s", md)
+	}
+	if css := HighlightCSS(DefaultHighlightStyle); !strings.Contains(css, ".chroma .kn") {
+		t.Errorf("unexpected css: %.200s", css)
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	got := parseLines("2, 4-6,x,7-y")
+	if want := [][2]int{{2, 2}, {4, 6}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLines = %v, want %v", got, want)
+	}
+}
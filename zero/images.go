@@ -0,0 +1,154 @@
+package zero
+
+import (
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindFigure is the node kind of a standalone image lifted out of its paragraph.
+var KindFigure = ast.NewNodeKind("Figure")
+
+// Figure holds an image that stood alone in a paragraph, possibly inside a
+// link. Its caption is the image's title.
+type Figure struct {
+	ast.BaseBlock
+	Caption []byte
+}
+
+func (n *Figure) Kind() ast.NodeKind { return KindFigure }
+
+func (n *Figure) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Caption": string(n.Caption)}, nil)
+}
+
+var imageResolverKey = parser.NewContextKey()
+
+// ResolveImages sets how images in the document parsed with pc find their asset
+// routes. resolve returns the route for a destination as written, or "" to fall
+// back to resolving it from the API's root.
+func ResolveImages(pc parser.Context, resolve func(dest string) string) {
+	pc.Set(imageResolverKey, resolve)
+}
+
+// images is the markdown extension for images: standalone ones become figures,
+// local ones are served from the API URL, and all load lazily with the
+// dimensions of their asset when it is a known raster image.
+type images struct {
+	apiURL string
+	asset  func(route string) (image.Config, bool)
+}
+
+func (i *images) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(i, 500)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(i, 500)))
+}
+
+func (i *images) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	resolve, _ := pc.Get(imageResolverKey).(func(string) string)
+	var found []*ast.Image
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if img, ok := n.(*ast.Image); ok && entering {
+			found = append(found, img)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, img := range found {
+		img.SetAttributeString("loading", []byte("lazy"))
+		i.source(img, resolve)
+		lift(img)
+	}
+}
+
+// source points a local image at its route on the API and sizes it.
+func (i *images) source(img *ast.Image, resolve func(string) string) {
+	dest := string(img.Destination)
+	var route string
+	if resolve != nil {
+		route = resolve(dest)
+	}
+	if route == "" {
+		route = i.route(dest)
+	}
+	if route == "" {
+		return
+	}
+	img.Destination = []byte(i.apiURL + route)
+	if _, ok := img.AttributeString("width"); ok || i.asset == nil {
+		return
+	}
+	if cfg, ok := i.asset(route); ok && cfg.Width > 0 {
+		img.SetAttributeString("width", []byte(strconv.Itoa(cfg.Width)))
+		img.SetAttributeString("height", []byte(strconv.Itoa(cfg.Height)))
+	}
+}
+
+// route resolves a local destination from the API's root. Served routes drop
+// file extensions, so the bare route is used when only it is served.
+func (i *images) route(dest string) string {
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return ""
+	}
+	route := path.Join("/", u.Path)
+	if i.asset != nil {
+		if _, ok := i.asset(route); !ok {
+			bare := route[:len(route)-len(path.Ext(route))]
+			if _, ok := i.asset(bare); ok {
+				route = bare
+			}
+		}
+	}
+	if u.RawQuery != "" {
+		route += "?" + u.RawQuery
+	}
+	if u.Fragment != "" {
+		route += "#" + u.Fragment
+	}
+	return route
+}
+
+// lift replaces the paragraph an image stands alone in with a figure.
+func lift(img *ast.Image) {
+	var n ast.Node = img
+	if link, ok := n.Parent().(*ast.Link); ok && link.ChildCount() == 1 {
+		n = link
+	}
+	p, ok := n.Parent().(*ast.Paragraph)
+	if !ok || p.ChildCount() != 1 {
+		return
+	}
+	fig := &Figure{Caption: img.Title}
+	img.Title = nil
+	p.Parent().ReplaceChild(p.Parent(), p, fig)
+	fig.AppendChild(fig, n)
+}
+
+func (i *images) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindFigure, i.renderFigure)
+}
+
+func (i *images) renderFigure(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.WriteString("<figure>\n")
+		return ast.WalkContinue, nil
+	}
+	if caption := node.(*Figure).Caption; len(caption) > 0 {
+		w.WriteString("\n<figcaption>")
+		w.Write(util.EscapeHTML(caption))
+		w.WriteString("</figcaption>")
+	}
+	w.WriteString("\n</figure>\n")
+	return ast.WalkContinue, nil
+}
@@ -0,0 +1,35 @@
+package zero
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestImages(t *testing.T) {
+	var pic bytes.Buffer
+	png.Encode(&pic, image.NewRGBA(image.Rect(0, 0, 64, 48)))
+	z := New(WithAPIURL("http://api.test")).(*zeroImpl)
+	z.AddContent("/img/logo", "image/png", pic.Bytes())
+
+	src := []byte("![logo](img/logo.png \"The logo\")\n\n[![a](/img/a.png)](https://x.test)\n\nText ![b](https://cdn.test/b.png) inline.\n")
+	var b bytes.Buffer
+	if err := (*z.Markdown()).Convert(src, &b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"<figure>\n" + `<img src="http://api.test/img/logo" alt="logo" loading="lazy" width="64" height="48" />` + "\n<figcaption>The logo</figcaption>\n</figure>",
+		"<figure>\n" + `<a href="https://x.test"><img src="http://api.test/img/a.png" alt="a" loading="lazy" /></a>` + "\n</figure>",
+		`<p>Text <img src="https://cdn.test/b.png" alt="b" loading="lazy" /> inline.</p>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `title=`) {
+		t.Errorf("caption left as title:\n%s", out)
+	}
+}
@@ -0,0 +1,288 @@
+package zero
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// ErrFrameExists is returned when registering a name another frame already has.
+var ErrFrameExists = errors.New("frame name already registered")
+
+// entry is a frame in the index, optionally addressable by name.
+type entry struct {
+	name  string
+	hash  string
+	frame *One
+	body  *encoded
+	meta  *Meta
+}
+
+func (f *forge) newEntry(name string, frame *One) *entry {
+	e := &entry{name: name, frame: frame}
+	if frame != nil {
+		e.body = encode([]byte(*frame), f.encodings)
+		e.hash = e.body.hash
+	}
+	return e
+}
+
+func (e *entry) event(kind string, index int) Event {
+	return Event{Type: kind, Index: index, Name: e.name, Hash: e.hash}
+}
+
+// FrameInfo describes the position, name, content hash and metadata of an indexed frame.
+type FrameInfo struct {
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+	Hash  string `json:"hash"`
+	Meta  *Meta  `json:"meta,omitempty"`
+}
+
+func (f *forge) GetFrame(idx int) *One {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if idx < 0 || idx >= len(f.index) {
+		return nil
+	}
+	return f.index[idx].frame
+}
+
+// Resolve maps a frame key, either a name or a numeric index, to its position in the index.
+func (f *forge) Resolve(key string) (int, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.resolve(key)
+}
+
+func (f *forge) resolve(key string) (int, bool) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return 0, false
+	}
+	if i, err := strconv.Atoi(key); err == nil {
+		return i, i >= 0 && i < len(f.index)
+	}
+	i, ok := f.names[strings.ToLower(key)]
+	return i, ok
+}
+
+func (f *forge) Index() []FrameInfo {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	info := make([]FrameInfo, len(f.index))
+	for i, e := range f.index {
+		info[i] = FrameInfo{Index: i, Name: e.name, Hash: e.hash, Meta: e.meta}
+	}
+	return info
+}
+
+func (f *forge) Frames() int {
+	return f.Count()
+}
+
+func (f *forge) Count() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.index)
+}
+
+func (f *forge) HandleFrame(w http.ResponseWriter, r *http.Request) {
+	// Resolve the frame and read the count from a single snapshot so concurrent
+	// mutations never pair a frame with the wrong position.
+	f.mu.RLock()
+	count := len(f.index)
+	current := 0
+	name, byPath := mux.Vars(r)["name"]
+	if !byPath {
+		name = r.Header.Get("X-Frame")
+	}
+	i, found := f.resolve(name)
+	if found {
+		current = i
+	}
+	var e *entry
+	if current < count {
+		e = f.index[current]
+	}
+	f.mu.RUnlock()
+
+	if byPath && !found {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Frames", strconv.Itoa(count))
+	w.Header().Set("X-Frame", strconv.Itoa(current))
+	if e == nil || e.body == nil {
+		return
+	}
+	if e.name != "" {
+		w.Header().Set("X-Frame-Name", e.name)
+	}
+	w.Header().Add("Vary", "X-Frame")
+	e.body.serve(w, r)
+}
+
+func (f *forge) UpdateIndex(frame *One) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e := f.newEntry("", frame)
+	f.index = append(f.index, e)
+	f.events.publish(e.event(FrameAdded, len(f.index)-1))
+}
+
+// Register appends frame to the index under a stable name, so clients can address it
+// as X-Frame: name or /frame/name regardless of its position.
+func (f *forge) Register(name string, frame *One) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.insert(len(f.index), name, frame)
+}
+
+// InsertFrame places frame at pos, shifting later frames back. An empty name indexes
+// the frame by position only.
+func (f *forge) InsertFrame(pos int, name string, frame *One) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.insert(pos, name, frame)
+}
+
+// ReplaceFrame swaps the content of the frame addressed by key, keeping its name,
+// position and metadata.
+func (f *forge) ReplaceFrame(key string, frame *One) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i, ok := f.resolve(key)
+	if !ok {
+		return fmt.Errorf("frame %q not found", key)
+	}
+	f.replace(i, frame)
+	return nil
+}
+
+// put replaces the frame registered as name, or appends frame under name. A name
+// the index rejects appends the frame unnamed.
+func (f *forge) put(name string, frame *One) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name, err := frameName(name)
+	if err != nil {
+		name = ""
+	}
+	if i, ok := f.names[name]; ok {
+		f.replace(i, frame)
+		return
+	}
+	f.insert(len(f.index), name, frame)
+}
+
+// replace swaps the content of the frame at i; callers must hold the write lock.
+func (f *forge) replace(i int, frame *One) {
+	e := f.newEntry(f.index[i].name, frame)
+	e.meta = f.index[i].meta
+	f.index[i] = e
+	f.events.publish(e.event(FrameUpdated, i))
+}
+
+// SetMeta attaches metadata to the frame addressed by key, replacing any it had;
+// nil removes it.
+func (f *forge) SetMeta(key string, meta *Meta) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i, ok := f.resolve(key)
+	if !ok {
+		return fmt.Errorf("frame %q not found", key)
+	}
+	e := *f.index[i]
+	e.meta = meta
+	f.index[i] = &e
+	f.events.publish(e.event(FrameUpdated, i))
+	return nil
+}
+
+func (f *forge) RemoveFrame(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i, ok := f.resolve(key)
+	if !ok {
+		return fmt.Errorf("frame %q not found", key)
+	}
+	e := f.index[i]
+	f.index = append(f.index[:i:i], f.index[i+1:]...)
+	f.reindex()
+	f.events.publish(e.event(FrameRemoved, i))
+	return nil
+}
+
+// MoveFrame relocates the frame addressed by key to pos in the final order.
+func (f *forge) MoveFrame(key string, pos int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i, ok := f.resolve(key)
+	if !ok {
+		return fmt.Errorf("frame %q not found", key)
+	}
+	if pos < 0 || pos >= len(f.index) {
+		return fmt.Errorf("position %d out of range [0, %d)", pos, len(f.index))
+	}
+	e := f.index[i]
+	index := append(f.index[:i:i], f.index[i+1:]...)
+	f.index = append(index[:pos:pos], append([]*entry{e}, index[pos:]...)...)
+	f.reindex()
+	f.events.publish(e.event(FrameMoved, pos))
+	return nil
+}
+
+// insert adds a frame at pos; callers must hold the write lock. The index slice is
+// always rebuilt rather than modified in place so snapshots taken by readers stay valid.
+func (f *forge) insert(pos int, name string, frame *One) error {
+	if pos < 0 || pos > len(f.index) {
+		return fmt.Errorf("position %d out of range [0, %d]", pos, len(f.index))
+	}
+	if name != "" {
+		var err error
+		if name, err = frameName(name); err != nil {
+			return err
+		}
+		if _, ok := f.names[name]; ok {
+			return fmt.Errorf("frame %q: %w", name, ErrFrameExists)
+		}
+	}
+	e := f.newEntry(name, frame)
+	f.index = append(f.index[:pos:pos], append([]*entry{e}, f.index[pos:]...)...)
+	f.reindex()
+	f.events.publish(e.event(FrameAdded, pos))
+	return nil
+}
+
+// reindex rebuilds the name lookup after the order changed; callers must hold the write lock.
+func (f *forge) reindex() {
+	names := make(map[string]int, len(f.names))
+	for i, e := range f.index {
+		if e.name != "" {
+			names[e.name] = i
+		}
+	}
+	f.names = names
+}
+
+// frameName normalizes a frame name; names are case-insensitive and may not be numeric,
+// since numeric keys always address positions.
+func frameName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("frame name is empty")
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return "", fmt.Errorf("frame name %q is numeric", name)
+	}
+	if strings.ContainsAny(name, "/?#") {
+		return "", fmt.Errorf("frame name %q contains a reserved character", name)
+	}
+	return name, nil
+}
@@ -0,0 +1,174 @@
+package zero
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func frame(s string) *One {
+	o := One(s)
+	return &o
+}
+
+func TestIndexMutations(t *testing.T) {
+	f := NewForge()
+	f.UpdateIndex(frame("a"))
+	if err := f.Register("B", frame("b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Register("b", frame("dup")); err == nil {
+		t.Fatal("expected duplicate name to fail")
+	}
+	if err := f.Register("3", frame("x")); err == nil {
+		t.Fatal("expected numeric name to fail")
+	}
+	if err := f.InsertFrame(0, "c", frame("c")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.MoveFrame("c", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.ReplaceFrame("b", frame("b2")); err != nil {
+		t.Fatal(err)
+	}
+	got := ""
+	for i := 0; i < f.Count(); i++ {
+		got += string(*f.GetFrame(i))
+	}
+	if got != "ab2c" {
+		t.Fatalf("order = %q, want %q", got, "ab2c")
+	}
+	if i, ok := f.Resolve("C"); !ok || i != 2 {
+		t.Fatalf("Resolve(C) = %d, %v", i, ok)
+	}
+	if err := f.RemoveFrame("0"); err != nil {
+		t.Fatal(err)
+	}
+	if i, ok := f.Resolve("c"); !ok || i != 1 {
+		t.Fatalf("Resolve(c) after remove = %d, %v", i, ok)
+	}
+	if err := f.RemoveFrame("a"); err == nil {
+		t.Fatal("expected missing frame to fail")
+	}
+}
+
+func TestNamedFrames(t *testing.T) {
+	z := NewZero("", "")
+	z.Build("", true, Text("first"))
+	z.Build("", true, Named("Readme"), Text("v1"))
+	z.Build("", true, Named("readme"), Text("v2"))
+	z.Build("", true, Named("7"), Text("numeric"))
+	if got := z.Count(); got != 3 {
+		t.Fatalf("Count = %d, want 3", got)
+	}
+
+	get := func(path, header string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		if header != "" {
+			r.Header.Set("X-Frame", header)
+		}
+		w := httptest.NewRecorder()
+		z.Router().ServeHTTP(w, r)
+		return w
+	}
+	for _, w := range []*httptest.ResponseRecorder{get("/frame/README", ""), get("/frame", "readme")} {
+		if w.Code != http.StatusOK || w.Body.String() != "v2" {
+			t.Fatalf("got %d %q, want the rebuilt frame", w.Code, w.Body.String())
+		}
+		if w.Header().Get("X-Frame") != "1" || w.Header().Get("X-Frame-Name") != "readme" || w.Header().Get("X-Frames") != "3" {
+			t.Errorf("headers = %v", w.Header())
+		}
+	}
+	if w := get("/frame/2", ""); w.Body.String() != "numeric" || w.Header().Get("X-Frame-Name") != "" {
+		t.Errorf("numeric name: got %q named %q", w.Body.String(), w.Header().Get("X-Frame-Name"))
+	}
+	if w := get("/frame/missing", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown name: got %d", w.Code)
+	}
+	if w := get("/frame", "missing"); w.Body.String() != "first" {
+		t.Errorf("unknown X-Frame: got %q, want the first frame", w.Body.String())
+	}
+}
+
+func TestHandleFrameVary(t *testing.T) {
+	z := New(WithOrigins("https://a.test", "https://b.test"))
+	z.UpdateIndex(frame("a"))
+	r := httptest.NewRequest("GET", "/frame", nil)
+	r.Header.Set("Origin", "https://a.test")
+	w := httptest.NewRecorder()
+	z.Router().ServeHTTP(w, r)
+	vary := strings.Join(w.Header().Values("Vary"), ", ")
+	for _, want := range []string{"Origin", "X-Frame", "Accept-Encoding"} {
+		if !strings.Contains(vary, want) {
+			t.Errorf("Vary = %q, missing %s", vary, want)
+		}
+	}
+}
+
+func TestHandleFrameConcurrentMutation(t *testing.T) {
+	z := NewZero("", "")
+	for i := 0; i < 4; i++ {
+		if err := z.Register(fmt.Sprintf("f%d", i), frame(fmt.Sprintf("f%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := httptest.NewServer(z.Router())
+	defer srv.Close()
+
+	var writer, readers sync.WaitGroup
+	stop := make(chan struct{})
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			name := fmt.Sprintf("x%d", i)
+			for _, err := range []error{
+				z.InsertFrame(0, name, frame(name)),
+				z.MoveFrame(name, z.Count()-1),
+				z.ReplaceFrame(name, frame(name+"!")),
+				z.RemoveFrame(name),
+			} {
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}
+	}()
+
+	for w := 0; w < 8; w++ {
+		readers.Add(1)
+		go func(w int) {
+			defer readers.Done()
+			for i := 0; i < 50; i++ {
+				key := "f" + strconv.Itoa((w+i)%4)
+				req, _ := http.NewRequest("GET", srv.URL+"/frame", nil)
+				req.Header.Set("X-Frame", key)
+				res, err := http.DefaultClient.Do(req)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				res.Body.Close()
+				if got := res.Header.Get("X-Frame-Name"); got != key {
+					t.Errorf("X-Frame-Name = %q, want %q", got, key)
+				}
+			}
+		}(w)
+	}
+
+	// Stop the writer only after the readers finish so every request races a mutation.
+	readers.Wait()
+	close(stop)
+	writer.Wait()
+}
@@ -0,0 +1,204 @@
+package zero
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	mathjax "github.com/litao91/goldmark-mathjax"
+	"github.com/wyatt915/treeblood"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// MathMode selects how markdown math ($...$, $$...$$) is rendered.
+type MathMode int
+
+const (
+	// MathJax leaves TeX in place for a MathJax script in the page.
+	MathJax MathMode = iota
+	// MathML converts TeX to MathML on the server, so frames display math offline
+	// and without third-party scripts. Formulas that fail to convert fall back to
+	// MathJax markup.
+	MathML
+)
+
+// mathExtension returns the goldmark extension for mode. Both modes parse math
+// like goldmark-mathjax; its renderer writes TeX unescaped, so math is always
+// rendered here.
+func mathExtension(mode MathMode) goldmark.Extender {
+	return mathML{server: mode == MathML}
+}
+
+// mathML renders math as MathML with treeblood when server is set, and as
+// escaped TeX for MathJax otherwise or when conversion fails.
+type mathML struct {
+	server bool
+}
+
+func (r mathML) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(mathjax.NewMathJaxBlockParser(), 701)),
+		parser.WithInlineParsers(util.Prioritized(mathjax.NewInlineMathParser(), 501)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(r, 501)))
+}
+
+func (r mathML) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(mathjax.KindInlineMath, r.renderInline)
+	reg.Register(mathjax.KindMathBlock, r.renderBlock)
+}
+
+func (r mathML) renderInline(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	var tex bytes.Buffer
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		value := c.(*ast.Text).Segment.Value(source)
+		if trimmed, ok := bytes.CutSuffix(value, []byte("\n")); ok {
+			tex.Write(trimmed)
+			if c != n.LastChild() {
+				tex.WriteByte(' ')
+			}
+		} else {
+			tex.Write(value)
+		}
+	}
+	if mml, ok := r.convert(tex.String(), false); ok {
+		w.WriteString(mml)
+	} else {
+		w.WriteString(`<span class="math inline">\(` + html.EscapeString(tex.String()) + `\)</span>`)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r mathML) renderBlock(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	var tex bytes.Buffer
+	lines := n.Lines()
+	for i := range lines.Len() {
+		line := lines.At(i)
+		tex.Write(line.Value(source))
+	}
+	if mml, ok := r.convert(tex.String(), true); ok {
+		w.WriteString(mml + "\n")
+	} else {
+		w.WriteString(`<p><span class="math display">\[` + html.EscapeString(tex.String()) + `\]</span></p>` + "\n")
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r mathML) convert(tex string, display bool) (string, bool) {
+	if !r.server {
+		return "", false
+	}
+	return texToMathML(tex, display)
+}
+
+// texToMathML converts tex, reporting false when treeblood fails or marks part
+// of the formula as an error.
+func texToMathML(tex string, display bool) (string, bool) {
+	convert := treeblood.InlineStyle
+	if display {
+		convert = treeblood.DisplayStyle
+	}
+	mml, err := convert(strings.TrimSpace(tex), nil)
+	if err != nil || mml == "" || strings.Contains(mml, "<merror") {
+		return "", false
+	}
+	return sanitizeMathML(strings.TrimSpace(mml))
+}
+
+// mathElements are the MathML elements treeblood emits. Token elements hold
+// only text, so anything tag-like inside them came from the TeX source.
+var mathElements = map[string]bool{
+	"math": false, "semantics": false, "annotation": true, "mrow": false,
+	"mi": true, "mn": true, "mo": true, "ms": true, "mtext": true, "mspace": true,
+	"msub": false, "msup": false, "msubsup": false, "munder": false, "mover": false, "munderover": false,
+	"mfrac": false, "msqrt": false, "mroot": false, "mstyle": false, "mpadded": false, "mphantom": false,
+	"menclose": false, "mtable": false, "mtr": false, "mtd": false, "mlabeledtr": false,
+	"mmultiscripts": false, "mprescripts": false, "none": false, "maligngroup": true, "malignmark": true,
+}
+
+var mathAttrs = map[string]bool{
+	"display": true, "displaystyle": true, "xmlns": true, "mathvariant": true, "mathcolor": true,
+	"mathbackground": true, "mathsize": true, "encoding": true, "form": true, "stretchy": true,
+	"fence": true, "separator": true, "lspace": true, "rspace": true, "largeop": true,
+	"movablelimits": true, "accent": true, "accentunder": true, "symmetric": true, "minsize": true,
+	"maxsize": true, "linethickness": true, "width": true, "height": true, "depth": true,
+	"voffset": true, "scriptlevel": true, "rowspacing": true, "columnspacing": true,
+	"columnalign": true, "rowalign": true, "columnlines": true, "rowlines": true, "frame": true,
+	"framespacing": true, "notation": true, "intent": true, "class": true, "title": true,
+	"style": true, "columnspan": true, "rowspan": true, "bevelled": true,
+}
+
+var (
+	mathTagRe    = regexp.MustCompile(`^<(/?)([a-z]+)((?:\s+[a-z-]+="[^"]*")*)\s*>`)
+	mathAttrRe   = regexp.MustCompile(`([a-z-]+)="([^"]*)"`)
+	mathEntityRe = regexp.MustCompile(`^&(?:[A-Za-z][A-Za-z0-9]*|#[0-9]+|#x[0-9A-Fa-f]+);`)
+	mathStyleRe  = regexp.MustCompile(`^[A-Za-z0-9 .,:;'#%+-]*$`)
+)
+
+// sanitizeMathML rewrites treeblood's output keeping only allowlisted elements
+// and attributes. treeblood copies text such as \text{...} verbatim, so text is
+// escaped, keeping only well-formed entities. Unbalanced markup is rejected.
+func sanitizeMathML(mml string) (string, bool) {
+	var b strings.Builder
+	var open []string
+	for i := 0; i < len(mml); {
+		leaf := len(open) > 0 && mathElements[open[len(open)-1]]
+		if m := mathTagRe.FindStringSubmatch(mml[i:]); m != nil {
+			closing, name := m[1] == "/", m[2]
+			if closing && len(open) > 0 && open[len(open)-1] == name {
+				open = open[:len(open)-1]
+				b.WriteString("</" + name + ">")
+				i += len(m[0])
+				continue
+			}
+			if _, ok := mathElements[name]; ok && !closing && !leaf {
+				open = append(open, name)
+				b.WriteString("<" + name)
+				for _, a := range mathAttrRe.FindAllStringSubmatch(m[3], -1) {
+					key, value := a[1], html.UnescapeString(a[2])
+					if !mathAttrs[key] || (key == "style" && !mathStyleRe.MatchString(value)) ||
+						(key == "xmlns" && value != "http://www.w3.org/1998/Math/MathML") {
+						continue
+					}
+					b.WriteString(" " + key + `="` + html.EscapeString(value) + `"`)
+				}
+				b.WriteString(">")
+				i += len(m[0])
+				continue
+			}
+		}
+		switch c := mml[i]; c {
+		case '&':
+			if e := mathEntityRe.FindString(mml[i:]); e != "" {
+				b.WriteString(e)
+				i += len(e)
+				continue
+			}
+			b.WriteString("&amp;")
+		case '<':
+			b.WriteString("&lt;")
+		case '>':
+			b.WriteString("&gt;")
+		case '"':
+			b.WriteString("&#34;")
+		default:
+			b.WriteByte(c)
+		}
+		i++
+	}
+	if len(open) > 0 {
+		return "", false
+	}
+	return b.String(), true
+}
@@ -0,0 +1,44 @@
+package zero
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMathML(t *testing.T) {
+	src := []byte("Inline $x^2 < y$ ok.\n\n$$\n\\frac{a}{b}\n$$\n\nbad $\\nosuchcommand{<x>}$\n")
+	render := func(mode MathMode) string {
+		var b bytes.Buffer
+		if err := (*newElement(newConfig(WithMath(mode))).Markdown()).Convert(src, &b); err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+
+	out := render(MathML)
+	for _, want := range []string{
+		`<p>Inline <math `,
+		`display="inline"`,
+		`<mo>&lt;</mo>`,
+		`display="block"`,
+		`<mfrac>`,
+		`<p>bad <span class="math inline">\(\nosuchcommand{&lt;x&gt;}\)</span></p>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("MathML output missing %s:\n%s", want, out)
+		}
+	}
+
+	if out := render(MathJax); strings.Contains(out, "<math") || !strings.Contains(out, `<span class="math display">\[`) {
+		t.Errorf("MathJax output:\n%s", out)
+	}
+
+	src = []byte("$\\text{<script>alert(1)</script>}$ and $\\text{</mtext></math><img src=x onerror=alert(1)>}$\n")
+	if out := render(MathML); strings.Contains(out, "<script") || strings.Contains(out, "<img") || !strings.Contains(out, "<mtext>&lt;script&gt;alert(1)&lt;/script&gt;</mtext>") {
+		t.Errorf("MathML text not escaped:\n%s", out)
+	}
+	if out := render(MathJax); strings.Contains(out, "<script") || strings.Contains(out, "<img") {
+		t.Errorf("MathJax TeX not escaped:\n%s", out)
+	}
+}
@@ -0,0 +1,68 @@
+package zero
+
+import (
+	"encoding/json"
+	"html/template"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"go.abhg.dev/goldmark/frontmatter"
+)
+
+// Meta is per-frame metadata, usually from markdown front matter. Attached to a
+// frame with SetMeta it is listed in the index and the manifest; rendered into
+// the frame it is picked up by the runtime, which sets the page title and lists
+// Keys among the keybindings while the frame is shown.
+type Meta struct {
+	Title  string `json:"title,omitempty" yaml:"title" toml:"title"`
+	Class  string `json:"class,omitempty" yaml:"class" toml:"class"`
+	Weight int    `json:"weight,omitempty" yaml:"weight" toml:"weight"`
+	// CSS and JS are stylesheet and script URLs the frame includes.
+	CSS []string `json:"css,omitempty" yaml:"css" toml:"css"`
+	JS  []string `json:"js,omitempty" yaml:"js" toml:"js"`
+	// Keys maps keys the frame's scripts handle to their labels.
+	Keys  map[string]string `json:"keys,omitempty" yaml:"keys" toml:"keys"`
+	Draft bool              `json:"draft,omitempty" yaml:"draft" toml:"draft"`
+}
+
+// Render embeds m in a frame as inert JSON. A nil Meta renders as nothing.
+func (m *Meta) Render() One {
+	if m == nil {
+		return ""
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	// json.Marshal escapes <, > and &, so the data cannot close the script.
+	return One(template.HTML(`<script type="application/json" data-frame-meta>` + string(data) + `</script>`))
+}
+
+var frontMatterKey = parser.NewContextKey()
+
+// ParseFrontMatter reads a leading YAML (---) or TOML (+++) front matter block
+// in the document parsed with pc, for frontmatter.Get. Other documents keep a
+// leading --- as a thematic break.
+func ParseFrontMatter(pc parser.Context) {
+	pc.Set(frontMatterKey, true)
+}
+
+// frontMatter is the front matter block parser, active only in documents
+// parsed with ParseFrontMatter.
+type frontMatter struct {
+	frontmatter.Parser
+}
+
+func (p *frontMatter) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithBlockParsers(util.Prioritized(p, 0)))
+}
+
+func (p *frontMatter) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	if on, _ := pc.Get(frontMatterKey).(bool); !on {
+		return nil, parser.NoChildren
+	}
+	return p.Parser.Open(parent, reader, pc)
+}
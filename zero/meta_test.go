@@ -0,0 +1,64 @@
+package zero
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"go.abhg.dev/goldmark/frontmatter"
+)
+
+func TestFrontMatterOptIn(t *testing.T) {
+	md := *NewElement().Markdown()
+	src := []byte("---\ntitle: x\n---\n\nbody\n")
+
+	var b bytes.Buffer
+	if err := md.Convert(src, &b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), "<hr />") {
+		t.Errorf("leading --- was not a thematic break: %s", b.String())
+	}
+
+	pc := parser.NewContext()
+	ParseFrontMatter(pc)
+	doc := md.Parser().Parse(text.NewReader(src), parser.WithContext(pc))
+	var meta Meta
+	if data := frontmatter.Get(pc); data == nil || data.Decode(&meta) != nil || meta.Title != "x" {
+		t.Fatalf("front matter = %+v", meta)
+	}
+	b.Reset()
+	if err := md.Renderer().Render(&b, src, doc); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(b.String()); got != "<p>body</p>" {
+		t.Errorf("with front matter rendered %s", got)
+	}
+}
+
+func TestSetMeta(t *testing.T) {
+	f := NewForge()
+	if err := f.Register("a", frame("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetMeta("a", &Meta{Title: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.ReplaceFrame("a", frame("a2")); err != nil {
+		t.Fatal(err)
+	}
+	if m := f.Index()[0].Meta; m == nil || m.Title != "A" {
+		t.Fatalf("meta after replace = %+v", m)
+	}
+	if err := f.SetMeta("a", nil); err != nil {
+		t.Fatal(err)
+	}
+	if m := f.Index()[0].Meta; m != nil {
+		t.Fatalf("meta after clearing = %+v", m)
+	}
+	if err := f.SetMeta("missing", &Meta{}); err == nil {
+		t.Fatal("expected missing frame to fail")
+	}
+}
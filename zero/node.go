@@ -0,0 +1,294 @@
+package zero
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Renderable is anything that can be placed in a frame: built *One fragments,
+// *Node trees and Text.
+type Renderable interface {
+	Render() One
+}
+
+// Render returns the fragment itself; a nil *One renders as nothing.
+func (o *One) Render() One {
+	if o == nil {
+		return ""
+	}
+	return *o
+}
+
+// Text is escaped character data.
+type Text string
+
+func (t Text) Render() One {
+	return One(template.HTML(html.EscapeString(string(t))))
+}
+
+// voidElements have no closing tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+type attr struct {
+	key     string
+	value   string
+	boolean bool
+}
+
+// Node is an HTML element that stays editable until it is rendered, so
+// attributes, classes and children can be added after construction:
+//
+//	e.Img(src, "logo").Class("icon").Attr("loading", "lazy")
+type Node struct {
+	tag      string
+	attrs    []attr
+	children []Renderable
+}
+
+// El creates an element with the given children.
+func El(tag string, children ...Renderable) *Node {
+	return (&Node{tag: strings.ToLower(tag)}).Append(children...)
+}
+
+// Tag creates an element containing escaped text.
+func Tag(tag, text string) *Node {
+	return El(tag).Text(text)
+}
+
+// Append adds children; nil children are skipped and Attributes are applied
+// to n instead of being rendered.
+func (n *Node) Append(children ...Renderable) *Node {
+	for _, child := range children {
+		if isNil(child) {
+			continue
+		}
+		if a, ok := child.(Attribute); ok {
+			a(n)
+			continue
+		}
+		n.children = append(n.children, child)
+	}
+	return n
+}
+
+// With applies attribute options.
+func (n *Node) With(attrs ...Attribute) *Node {
+	for _, a := range attrs {
+		if a != nil {
+			a(n)
+		}
+	}
+	return n
+}
+
+// Text appends escaped text.
+func (n *Node) Text(s string) *Node {
+	if s == "" {
+		return n
+	}
+	return n.Append(Text(s))
+}
+
+// Attr sets an attribute, replacing any previous value. Event handlers (on*)
+// and style are ignored: frames script through JS, and inline CSS goes
+// through Style.
+func (n *Node) Attr(key, value string) *Node {
+	key = strings.ToLower(key)
+	if scriptable(key) {
+		return n
+	}
+	return n.set(attr{key: key, value: value})
+}
+
+// Bool sets or removes a boolean attribute such as controls or disabled.
+func (n *Node) Bool(key string, on bool) *Node {
+	key = strings.ToLower(key)
+	if !on {
+		return n.Remove(key)
+	}
+	if scriptable(key) {
+		return n
+	}
+	return n.set(attr{key: key, boolean: true})
+}
+
+// Style adds a CSS declaration to the style attribute. Declarations whose
+// property or value could break out of it or load resources are skipped.
+func (n *Node) Style(property, value string) *Node {
+	property = strings.ToLower(strings.TrimSpace(property))
+	value = strings.TrimSpace(value)
+	if !cssPropertyRe.MatchString(property) || !cssValueRe.MatchString(value) || cssFuncRe.MatchString(value) {
+		return n
+	}
+	decl := property + ": " + value
+	if current, ok := n.Get("style"); ok && current != "" {
+		decl = current + "; " + decl
+	}
+	return n.set(attr{key: "style", value: decl})
+}
+
+var (
+	cssPropertyRe = regexp.MustCompile(`^-?[a-z][a-z0-9-]*$`)
+	cssValueRe    = regexp.MustCompile(`^[A-Za-z0-9 #%.,+*/()!-]+$`)
+	cssFuncRe     = regexp.MustCompile(`(?i)(url|expression|image|image-set|element|attr|src)\s*\(`)
+)
+
+// scriptable reports whether an attribute can run script or restyle the page
+// unchecked, so it is only set through a dedicated API.
+func scriptable(key string) bool {
+	return key == "style" || strings.HasPrefix(key, "on")
+}
+
+// Remove deletes an attribute.
+func (n *Node) Remove(key string) *Node {
+	key = strings.ToLower(key)
+	n.attrs = slices.DeleteFunc(n.attrs, func(a attr) bool { return a.key == key })
+	return n
+}
+
+func (n *Node) set(a attr) *Node {
+	for i := range n.attrs {
+		if n.attrs[i].key == a.key {
+			n.attrs[i] = a
+			return n
+		}
+	}
+	n.attrs = append(n.attrs, a)
+	return n
+}
+
+// Get returns the value of an attribute.
+func (n *Node) Get(key string) (string, bool) {
+	key = strings.ToLower(key)
+	for _, a := range n.attrs {
+		if a.key == key {
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+// Class adds classes to the class attribute.
+func (n *Node) Class(classes ...string) *Node {
+	current, _ := n.Get("class")
+	all := strings.Fields(current)
+	for _, class := range classes {
+		all = append(all, strings.Fields(class)...)
+	}
+	if len(all) == 0 {
+		return n
+	}
+	return n.Attr("class", strings.Join(all, " "))
+}
+
+func (n *Node) ID(id string) *Node {
+	return n.Attr("id", id)
+}
+
+// Data sets a data-* attribute.
+func (n *Node) Data(key, value string) *Node {
+	return n.Attr("data-"+key, value)
+}
+
+// Aria sets an aria-* attribute.
+func (n *Node) Aria(key, value string) *Node {
+	return n.Attr("aria-"+key, value)
+}
+
+// Render serializes the tree. A nil *Node renders as nothing.
+func (n *Node) Render() One {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	n.write(&b)
+	return One(template.HTML(b.String()))
+}
+
+// One renders the tree into a fragment.
+func (n *Node) One() *One {
+	o := n.Render()
+	return &o
+}
+
+func (n *Node) write(b *strings.Builder) {
+	b.WriteByte('<')
+	b.WriteString(n.tag)
+	for _, a := range n.attrs {
+		if !validAttrName(a.key) {
+			continue
+		}
+		b.WriteByte(' ')
+		b.WriteString(a.key)
+		if a.boolean {
+			continue
+		}
+		b.WriteString(`="`)
+		b.WriteString(html.EscapeString(attrValue(a.key, a.value)))
+		b.WriteByte('"')
+	}
+	b.WriteByte('>')
+	if voidElements[n.tag] {
+		return
+	}
+	for _, child := range n.children {
+		if c, ok := child.(*Node); ok {
+			c.write(b)
+		} else {
+			b.WriteString(string(child.Render()))
+		}
+	}
+	b.WriteString("</")
+	b.WriteString(n.tag)
+	b.WriteByte('>')
+}
+
+// validAttrName rejects names that would break out of the tag.
+func validAttrName(name string) bool {
+	if name == "" {
+		return false
+	}
+	return !strings.ContainsAny(name, " \t\n\f\r\"'<>/=`")
+}
+
+// renderable uses Renderables as-is and formats anything else with %v as escaped
+// text. Nil values, including nil pointers, render as nothing.
+func renderable(v any) Renderable {
+	switch v := v.(type) {
+	case Renderable:
+		return v
+	case nil:
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		return renderable(rv.Elem().Interface())
+	}
+	return Text(fmt.Sprint(v))
+}
+
+// isNil reports whether r is nil or a typed nil pointer.
+func isNil(r Renderable) bool {
+	switch v := r.(type) {
+	case nil:
+		return true
+	case *One:
+		return v == nil
+	case *Node:
+		return v == nil
+	case Attribute:
+		return v == nil
+	}
+	return false
+}
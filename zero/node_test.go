@@ -0,0 +1,98 @@
+package zero
+
+import "testing"
+
+func TestNodeRender(t *testing.T) {
+	e := NewElement()
+	var none *One
+	var missing *Node
+	cases := []struct {
+		name string
+		node *Node
+		want string
+	}{
+		{"text escaped", e.Paragraph(`<b>"x"</b>`), `<p>&lt;b&gt;&#34;x&#34;&lt;/b&gt;</p>`},
+		{"linked img", e.LinkedImg("https://a", "/img/gh.png", ""), `<a href="https://a" target="_blank" rel="noopener"><img src="/img/gh.png" alt="gh"></a>`},
+		{"linked icon", e.LinkedIcon("https://a", "/img/x", "X"), `<a href="https://a" target="_blank" rel="noopener"><img src="/img/x" alt="X" class="icon"></a>`},
+		{"attrs escaped", e.Link(`/a?b=1&c="2"`, "go"), `<a href="/a?b=1&amp;c=&#34;2&#34;">go</a>`},
+		{"fluent", e.Span("s").ID("i").Class("a").Class("b c").Data("k", "v").Aria("label", "l"), `<span id="i" class="a b c" data-k="v" aria-label="l">s</span>`},
+		{"replace attr", El("div").Attr("title", "a").Attr("title", "b"), `<div title="b"></div>`},
+		{"bad attr name", El("div").Attr(`x" onload="y`, "z"), `<div></div>`},
+		{"nil children", e.Div("d", none, missing, e.Em("e")), `<div class="d"><em>e</em></div>`},
+		{"code block", e.CodeBlock("go", "a < b"), `<pre><code class="language-go">a &lt; b</code></pre>`},
+	}
+	for _, c := range cases {
+		if got := string(c.node.Render()); got != c.want {
+			t.Errorf("%s:\n got %s\nwant %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestBuildRenderables(t *testing.T) {
+	f := NewForge()
+	var missing *Node
+	raw := One("<hr>")
+	got := f.Build("", false, El("p").Text("a"), missing, &raw, Text("<"))
+	if want := `<p>a</p><hr>&lt;`; string(*got) != want {
+		t.Fatalf("Build = %s, want %s", *got, want)
+	}
+	got = f.Build("", false, ID("main"), El("p").Text("a"), Class("wide"))
+	if want := `<div id="main" class="wide"><p>a</p></div>`; string(*got) != want {
+		t.Fatalf("Build with attributes = %s, want %s", *got, want)
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	e := NewElement()
+	cases := []struct {
+		name string
+		node *Node
+		want string
+	}{
+		{"boolean", e.Video("/v/a", Controls(), Loop(), Poster("/img/a")), `<video src="/v/a" controls loop poster="/img/a"></video>`},
+		{"bool off", El("input").With(Disabled()).Bool("disabled", false), `<input>`},
+		{"lazy size", e.Img("/img/a", "a", Lazy(), Width(640), Height(480)), `<img src="/img/a" alt="a" loading="lazy" width="640" height="480">`},
+		{"sandbox", e.Iframe("https://x", Sandbox("allow-scripts")), `<iframe src="https://x" sandbox="allow-scripts"></iframe>`},
+		{"icon class", e.LinkedIcon("/", "/img/x", "X", Class("big")), `<a href="/" target="_blank" rel="noopener"><img src="/img/x" alt="X" class="icon big"></a>`},
+		{"div attrs", e.Div("d", Aria("label", "l"), Class("e"), e.Em("x")), `<div class="d e" aria-label="l"><em>x</em></div>`},
+		{"js url", e.Link("javascript:alert(1)", "x"), `<a href="#ZgotmplZ">x</a>`},
+		{"spaced js url", e.Link(" JavaScript:alert(1)", "x"), `<a href="#ZgotmplZ">x</a>`},
+		{"data image", e.Img("data:image/png;base64,AA", "a"), `<img src="data:image/png;base64,AA" alt="a">`},
+		{"data html", e.Iframe("data:text/html,<b>"), `<iframe src="#ZgotmplZ"></iframe>`},
+		{"relative colon", e.Link("/a:b", "x"), `<a href="/a:b">x</a>`},
+		{"handlers", e.Button("x", A("onclick", "alert(1)"), A("OnLoad", "x"), Bool("onerror")), `<button>x</button>`},
+		{"style", e.Span("x", A("style", "color: red"), Style("color", "red"), Style("margin", "0 auto")), `<span style="color: red; margin: 0 auto">x</span>`},
+		{"unsafe style", e.Span("x", Style("background", "url(/a)"), Style("color", `red" onclick="x`), Style("x;y", "1")), `<span>x</span>`},
+		{"srcset", e.Img("/a", "a", A("srcset", "/a 1x, javascript:x 2x")), `<img src="/a" alt="a" srcset="/a 1x, #ZgotmplZ 2x">`},
+	}
+	for _, c := range cases {
+		if got := string(c.node.Render()); got != c.want {
+			t.Errorf("%s:\n got %s\nwant %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	e := NewElement()
+	raw := One("<code>x</code>")
+	cases := []struct {
+		name string
+		node *Node
+		want string
+	}{
+		{"rich items", e.List([]any{&raw, e.Link("/a", "a"), "<b>", 3, nil}, false),
+			`<ul><li><code>x</code></li><li><a href="/a">a</a></li><li>&lt;b&gt;</li><li>3</li><li></li></ul>`},
+		{"nested", e.List([]any{"a", []any{"a1", []any{"a1x"}}, "b"}, true, Start(3), Reversed()),
+			`<ol start="3" reversed><li>a<ol><li>a1<ol><li>a1x</li></ol></li></ol></li><li>b</li></ol>`},
+		{"leading sub-list", e.List([]any{[]any{"x"}}, false), `<ul><li><ul><li>x</li></ul></li></ul>`},
+		{"tasks", e.List([]any{Task{Done: true, Item: "done"}, Task{Item: "todo"}}, false),
+			`<ul><li><input checked="" disabled="" type="checkbox"> done</li><li><input disabled="" type="checkbox"> todo</li></ul>`},
+		{"description", e.DescriptionList([]Description{{Term: "Go", Details: []any{"language", e.Em("fast")}}}),
+			`<dl><dt>Go</dt><dd>language</dd><dd><em>fast</em></dd></dl>`},
+	}
+	for _, c := range cases {
+		if got := string(c.node.Render()); got != c.want {
+			t.Errorf("%s:\n got %s\nwant %s", c.name, got, c.want)
+		}
+	}
+}
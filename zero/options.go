@@ -0,0 +1,163 @@
+package zero
+
+import (
+	"cmp"
+	"log/slog"
+	"strings"
+
+	"github.com/yuin/goldmark"
+)
+
+// Option configures a Zero built with New.
+type Option func(*config)
+
+type config struct {
+	pathlessUrl string
+	apiURL      string
+	addr        string
+	origins     []string
+	headers     []string
+	methods     []string
+	extensions  []goldmark.Extender
+	logger      *slog.Logger
+	encodings   []string
+	collisions  CollisionPolicy
+	cache       map[string]string
+	// diskThreshold is the size above which files are served from disk; 0 keeps everything in memory.
+	diskThreshold int64
+	cacheSize     int64
+	// highlightStyle is the chroma style for code; empty, the default, leaves code unhighlighted.
+	highlightStyle string
+	lineNumbers    bool
+	math           MathMode
+}
+
+func newConfig(opts ...Option) *config {
+	c := &config{
+		addr:       ":1001",
+		headers:    []string{"Content-Type", "X-Frame", csrfHeader},
+		methods:    []string{"GET", "POST", "OPTIONS"},
+		logger:     slog.New(slog.DiscardHandler),
+		encodings:  defaultEncodings,
+		collisions: CollisionError,
+		cache:      map[string]string{"/": "no-cache"},
+		cacheSize:  64 << 20,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.apiURL == "" {
+		c.apiURL = "http://" + c.addr
+		if strings.HasPrefix(c.addr, ":") {
+			c.apiURL = "http://localhost" + c.addr
+		}
+	} else if !strings.HasPrefix(c.apiURL, "http://") && !strings.HasPrefix(c.apiURL, "https://") {
+		c.apiURL = "https://" + c.apiURL
+	}
+	if c.origins == nil {
+		c.origins = []string{"http://localhost:1000"}
+		if c.pathlessUrl != "" {
+			c.origins = []string{"https://" + c.pathlessUrl}
+		}
+	}
+	return c
+}
+
+// WithPathlessURL sets the host of the pathless client; it is allowed as a CORS
+// origin over https unless WithOrigins is given.
+func WithPathlessURL(host string) Option {
+	return func(c *config) { c.pathlessUrl = host }
+}
+
+// WithAPIURL sets the public URL of this server. A bare host is assumed to be https.
+func WithAPIURL(url string) Option {
+	return func(c *config) { c.apiURL = url }
+}
+
+// WithAddr sets the default address Serve listens on.
+func WithAddr(addr string) Option {
+	return func(c *config) { c.addr = addr }
+}
+
+// WithOrigins replaces the CORS origins derived from the pathless URL.
+func WithOrigins(origins ...string) Option {
+	return func(c *config) { c.origins = origins }
+}
+
+// WithCORSHeaders replaces the request headers allowed by CORS.
+func WithCORSHeaders(headers ...string) Option {
+	return func(c *config) { c.headers = headers }
+}
+
+// WithCORSMethods replaces the methods allowed by CORS.
+func WithCORSMethods(methods ...string) Option {
+	return func(c *config) { c.methods = methods }
+}
+
+// WithMarkdown adds goldmark extensions to the markdown renderer.
+func WithMarkdown(extensions ...goldmark.Extender) Option {
+	return func(c *config) { c.extensions = append(c.extensions, extensions...) }
+}
+
+// WithLogger sets the logger used for serving, watching and load errors. Logs are discarded by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) { c.logger = logger }
+}
+
+// WithCompression toggles compressing assets and frames; it is on by default.
+func WithCompression(enabled bool) Option {
+	return func(c *config) {
+		c.encodings = nil
+		if enabled {
+			c.encodings = defaultEncodings
+		}
+	}
+}
+
+// WithEncodings sets which content codings (Brotli, Zstd, Gzip) assets and frames are
+// compressed with, in order of preference when a client accepts several equally.
+func WithEncodings(encodings ...string) Option {
+	return func(c *config) { c.encodings = encodings }
+}
+
+// WithCacheControl sets the Cache-Control policy for routes under prefix. The most
+// specific prefix wins; everything defaults to "no-cache", i.e. revalidate by ETag.
+func WithCacheControl(prefix, policy string) Option {
+	return func(c *config) { c.cache[prefix] = policy }
+}
+
+// WithDiskThreshold serves files larger than size bytes straight from disk instead
+// of loading them, and their compressed variants, into memory.
+func WithDiskThreshold(size int64) Option {
+	return func(c *config) { c.diskThreshold = size }
+}
+
+// WithCacheSize bounds the memory, in bytes, used for compressed variants of files
+// served from disk. Least recently used variants are evicted first; files larger
+// than the cache are always sent uncompressed.
+func WithCacheSize(size int64) Option {
+	return func(c *config) { c.cacheSize = size }
+}
+
+// WithCollisionPolicy sets how AddFile and AddPath resolve two files claiming the
+// same route. The default, CollisionError, keeps the first file and reports an error.
+func WithCollisionPolicy(policy CollisionPolicy) Option {
+	return func(c *config) { c.collisions = policy }
+}
+
+// WithHighlighting highlights code blocks and fenced markdown code on the server
+// with a chroma style, DefaultHighlightStyle when style is empty. Without it code
+// keeps a language-* class for a client-side highlighter.
+func WithHighlighting(style string) Option {
+	return func(c *config) { c.highlightStyle = cmp.Or(style, DefaultHighlightStyle) }
+}
+
+// WithLineNumbers numbers the lines of every highlighted code block.
+func WithLineNumbers(on bool) Option {
+	return func(c *config) { c.lineNumbers = on }
+}
+
+// WithMath selects how markdown math is rendered; the default is MathJax.
+func WithMath(mode MathMode) Option {
+	return func(c *config) { c.math = mode }
+}
@@ -0,0 +1,48 @@
+package zero
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"io"
+	"net/http"
+)
+
+// The pathless runtime and the shell page that hosts it make a frame server
+// usable in a browser without the separate pathless client.
+
+//go:embed runtime/pathless.js
+var pathlessJS []byte
+
+//go:embed runtime/index.html
+var shellHTML string
+
+var shell = template.Must(template.New("shell").Parse(shellHTML))
+
+// shellData fills the shell page; an empty ApiUrl means the page's own origin.
+type shellData struct {
+	ApiUrl   string
+	Runtime  string
+	Manifest string
+}
+
+func renderShell(w io.Writer, data shellData) error {
+	return shell.Execute(w, data)
+}
+
+func (f *fx) handleRuntime() {
+	var page bytes.Buffer
+	// The shell is served by this server, so the runtime talks to its own origin.
+	renderShell(&page, shellData{Runtime: "/pathless.js"})
+	f.handleStatic("/pathless.js", "text/javascript; charset=utf-8", pathlessJS)
+	f.handleStatic("/", "text/html; charset=utf-8", page.Bytes())
+}
+
+// handleStatic serves a fixed body at path, compressed once when first requested.
+func (f *fx) handleStatic(path, contentType string, data []byte) {
+	body := encode(data, f.encodings)
+	f.router.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		body.serve(w, r)
+	}).Methods("GET")
+}
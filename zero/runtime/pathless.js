@@ -0,0 +1,243 @@
+// pathless is the reference client runtime for frame servers. It loads frames
+// from /frame (or a static export's frames.json), runs their scripts, and gives
+// those scripts the pathless API: ctx, context, update, onKey, fetch, keybinds.
+(function () {
+  const root = document.getElementById('frame');
+  const manifest = window.frameManifest || null;
+  const cache = new Map();
+
+  const binds = new Map([
+    ['q', { label: 'previous frame', style: 'border-color: #8af' }],
+    ['e', { label: 'next frame', style: 'border-color: #8af' }],
+    ['w', { label: 'up' }],
+    ['a', { label: 'left' }],
+    ['s', { label: 'down' }],
+    ['d', { label: 'right' }],
+  ]);
+
+  let frames = 0;
+  let index = 0;
+  let name = '';
+  let handlers = [];
+  let generation = 0;
+  let offline = null;
+  let events = null;
+  let meta = {};
+  let frameKeys = [];
+  const baseTitle = document.title;
+
+  function stateKey() {
+    return 'pathless:' + (name || index);
+  }
+
+  function loadState() {
+    try {
+      return JSON.parse(sessionStorage.getItem(stateKey())) || {};
+    } catch (e) {
+      return {};
+    }
+  }
+
+  let state = {};
+
+  const pathless = {
+    ctx() {
+      return { frame: root, state };
+    },
+    context() {
+      return { panel: root, frame: root, state, index, name, frames, meta };
+    },
+    update(key, value) {
+      state[key] = value;
+      try {
+        sessionStorage.setItem(stateKey(), JSON.stringify(state));
+      } catch (e) {}
+    },
+    onKey(fn) {
+      const gen = generation;
+      handlers.push((k) => gen === generation && fn(k));
+    },
+    keybinds() {
+      return binds;
+    },
+    async fetch(url, opts = {}) {
+      const key = opts.key || url;
+      if (cache.has(key)) return cache.get(key);
+      // A static export stores assets as files named by the manifest.
+      if (offline && url.startsWith(apiUrl + '/')) {
+        url = offline.assets.get(url.slice(apiUrl.length)) || url;
+      }
+      const res = await window.fetch(url);
+      if (!res.ok) throw new Error(res.status + ' ' + url);
+      const type = res.headers.get('Content-Type') || '';
+      let data;
+      if (type.includes('json')) {
+        data = await res.json();
+      } else if (type.startsWith('text/')) {
+        data = await res.text();
+        try {
+          data = JSON.parse(data);
+        } catch (e) {}
+      } else {
+        data = URL.createObjectURL(await res.blob());
+      }
+      const result = { data, type };
+      cache.set(key, result);
+      return result;
+    },
+    go(key) {
+      return load(key);
+    },
+  };
+  window.pathless = pathless;
+
+  async function request(key) {
+    if (offline) {
+      const k = String(key);
+      const i = /^\d+$/.test(k) ? parseInt(k, 10) : offline.names.get(k.toLowerCase()) ?? 0;
+      const f = offline.frames[Math.min(Math.max(i, 0), offline.frames.length - 1)];
+      const res = await window.fetch(f.file);
+      return { html: await res.text(), index: f.index, name: f.name || '', frames: offline.frames.length };
+    }
+    const res = await window.fetch(apiUrl + '/frame', { headers: { 'X-Frame': String(key) } });
+    return {
+      html: await res.text(),
+      index: parseInt(res.headers.get('X-Frame') || '0', 10),
+      name: res.headers.get('X-Frame-Name') || '',
+      frames: parseInt(res.headers.get('X-Frames') || '0', 10),
+    };
+  }
+
+  async function load(key) {
+    const f = await request(key);
+    generation++;
+    handlers = [];
+    frames = f.frames;
+    index = f.index;
+    name = f.name;
+    state = loadState();
+
+    root.innerHTML = f.html;
+    applyMeta();
+    // Scripts inserted through innerHTML are inert; recreate them so they run
+    // in order while ctx() points at this frame. Data blocks stay inert.
+    for (const old of root.querySelectorAll('script')) {
+      if (old.type && !/^(text\/javascript|module)$/.test(old.type)) continue;
+      const s = document.createElement('script');
+      for (const a of old.attributes) s.setAttribute(a.name, a.value);
+      s.async = false;
+      s.textContent = old.textContent;
+      old.replaceWith(s);
+    }
+    history.replaceState(null, '', '#' + (name || index));
+  }
+
+  // applyMeta reads the frame's metadata block: its title names the page and its
+  // keys are listed among the keybindings until another frame loads.
+  function applyMeta() {
+    for (const k of frameKeys) binds.delete(k);
+    frameKeys = [];
+    const el = root.querySelector('script[data-frame-meta]');
+    try {
+      meta = el ? JSON.parse(el.textContent) : {};
+    } catch (e) {
+      meta = {};
+    }
+    document.title = meta.title ? meta.title + ' · ' + baseTitle : baseTitle;
+    for (const [k, label] of Object.entries(meta.keys || {})) {
+      if (binds.has(k)) continue;
+      binds.set(k, { label });
+      frameKeys.push(k);
+    }
+  }
+
+  document.addEventListener('keydown', (e) => {
+    if (e.target.closest && e.target.closest('input, textarea, select, [contenteditable]')) return;
+    if (e.key === 'q' && frames) return void load((index - 1 + frames) % frames);
+    if (e.key === 'e' && frames) return void load((index + 1) % frames);
+    for (const h of handlers) h(e.key);
+  });
+
+  window.addEventListener('hashchange', () => {
+    const key = decodeURIComponent(location.hash.slice(1));
+    if (key && key !== name && key !== String(index)) load(key);
+  });
+
+  // Forms built with data-form are posted in the background with a CSRF token
+  // from their action and the cookie it is bound to. Results land in
+  // [data-form-status], and invalid fields are marked aria-invalid. A
+  // form-result event carries the parsed response.
+  document.addEventListener('submit', async (e) => {
+    const form = e.target;
+    if (!form.matches || !form.matches('form[data-form]')) return;
+    e.preventDefault();
+    let status = form.querySelector('[data-form-status]');
+    if (!status) {
+      status = document.createElement('p');
+      status.setAttribute('data-form-status', '');
+      status.setAttribute('role', 'status');
+      form.append(status);
+    }
+    for (const el of form.querySelectorAll('[aria-invalid]')) el.removeAttribute('aria-invalid');
+    try {
+      // The token is only valid with the cookie issued alongside it.
+      const { token } = await (await window.fetch(form.action, { credentials: 'include' })).json();
+      const res = await window.fetch(form.action, {
+        method: 'POST',
+        credentials: 'include',
+        headers: { 'X-CSRF-Token': token, Accept: 'application/json' },
+        body: new URLSearchParams(new FormData(form)),
+      });
+      const data = await res.json();
+      const errors = Object.entries(data.errors || {});
+      for (const [field] of errors) {
+        const el = form.elements[field];
+        if (el && el.setAttribute) el.setAttribute('aria-invalid', 'true');
+      }
+      status.textContent = data.message || errors.map(([f, m]) => f + ' ' + m).join('; ');
+      if (data.ok) form.reset();
+      form.dispatchEvent(new CustomEvent('form-result', { detail: data, bubbles: true }));
+      // With a live event stream the frame-updated event reloads instead.
+      if (data.frame && !events && (data.frame === name || data.frame === String(index))) load(name || index);
+    } catch (err) {
+      status.textContent = err.message;
+    }
+  });
+
+  function listen() {
+    if (!window.EventSource) return;
+    events = new EventSource(apiUrl + '/events');
+    const reload = () => load(name || index);
+    events.addEventListener('frame-updated', (e) => {
+      const d = JSON.parse(e.data);
+      if (d.index === index || (name && d.name === name)) reload();
+    });
+    for (const type of ['frame-added', 'frame-removed', 'frame-moved']) {
+      events.addEventListener(type, reload);
+    }
+    for (const type of ['asset-updated', 'asset-removed', 'resync']) {
+      events.addEventListener(type, () => {
+        cache.clear();
+        reload();
+      });
+    }
+  }
+
+  async function start() {
+    if (manifest) {
+      const m = await (await window.fetch(manifest)).json();
+      offline = {
+        frames: m.frames,
+        names: new Map(m.frames.filter((f) => f.name).map((f) => [f.name, f.index])),
+        assets: new Map((m.assets || []).map((a) => [a.path, a.file])),
+      };
+    } else {
+      listen();
+    }
+    const hash = decodeURIComponent(location.hash.slice(1));
+    const key = hash === '' ? 0 : /^\d+$/.test(hash) ? parseInt(hash, 10) : hash;
+    await load(key);
+  }
+
+  start();
+})();
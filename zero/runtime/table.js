@@ -0,0 +1,47 @@
+// Sorts tables marked data-sortable when a column header is clicked and adds a
+// filter box above tables marked data-filterable. Safe to run more than once.
+(function () {
+  const text = (row, i) => {
+    const cell = row.cells[i];
+    return cell ? cell.dataset.sort ?? cell.textContent.trim() : '';
+  };
+  const compare = (a, b) => {
+    const x = Number(a), y = Number(b);
+    if (a !== '' && b !== '' && !isNaN(x) && !isNaN(y)) return x - y;
+    return a.localeCompare(b, undefined, { numeric: true, sensitivity: 'base' });
+  };
+  for (const table of document.querySelectorAll('table[data-sortable]:not([data-sort-ready])')) {
+    table.setAttribute('data-sort-ready', '');
+    const body = table.tBodies[0];
+    if (!body || !table.tHead) continue;
+    table.tHead.querySelectorAll('th').forEach((th, i) => {
+      th.tabIndex = 0;
+      th.style.cursor = 'pointer';
+      const sort = () => {
+        const asc = th.getAttribute('aria-sort') !== 'ascending';
+        for (const h of table.tHead.querySelectorAll('th')) h.removeAttribute('aria-sort');
+        th.setAttribute('aria-sort', asc ? 'ascending' : 'descending');
+        const rows = Array.from(body.rows).sort((a, b) => compare(text(a, i), text(b, i)));
+        if (!asc) rows.reverse();
+        body.append(...rows);
+      };
+      th.addEventListener('click', sort);
+      th.addEventListener('keydown', (e) => e.key === 'Enter' && sort());
+    });
+  }
+  for (const table of document.querySelectorAll('table[data-filterable]:not([data-filter-ready])')) {
+    table.setAttribute('data-filter-ready', '');
+    const input = document.createElement('input');
+    input.type = 'search';
+    input.className = 'table-filter';
+    input.placeholder = 'Filter';
+    input.setAttribute('aria-label', 'Filter table');
+    input.addEventListener('input', () => {
+      const q = input.value.toLowerCase();
+      for (const body of table.tBodies) {
+        for (const row of body.rows) row.hidden = q !== '' && !row.textContent.toLowerCase().includes(q);
+      }
+    });
+    table.before(input);
+  }
+})();
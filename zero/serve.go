@@ -0,0 +1,107 @@
+package zero
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ServeOption configures the listener and server used by Serve.
+type ServeOption func(*serveConfig)
+
+type serveConfig struct {
+	addr              string
+	listener          net.Listener
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	shutdownTimeout   time.Duration
+}
+
+// Addr sets the TCP address to listen on, overriding the address the Fx was built with.
+func Addr(addr string) ServeOption {
+	return func(c *serveConfig) { c.addr = addr }
+}
+
+// Listener serves on an existing listener instead of opening one, e.g. ":0" in tests.
+func Listener(l net.Listener) ServeOption {
+	return func(c *serveConfig) { c.listener = l }
+}
+
+func ReadTimeout(d time.Duration) ServeOption {
+	return func(c *serveConfig) { c.readTimeout = d }
+}
+
+func ReadHeaderTimeout(d time.Duration) ServeOption {
+	return func(c *serveConfig) { c.readHeaderTimeout = d }
+}
+
+// WriteTimeout bounds each response. Event streams opt out of it.
+func WriteTimeout(d time.Duration) ServeOption {
+	return func(c *serveConfig) { c.writeTimeout = d }
+}
+
+func IdleTimeout(d time.Duration) ServeOption {
+	return func(c *serveConfig) { c.idleTimeout = d }
+}
+
+// ShutdownTimeout bounds how long Serve waits for in-flight requests after ctx is cancelled.
+func ShutdownTimeout(d time.Duration) ServeOption {
+	return func(c *serveConfig) { c.shutdownTimeout = d }
+}
+
+// Serve listens and serves the router until ctx is cancelled, then shuts down
+// gracefully. Errors opening the listener are returned immediately.
+func (f *fx) Serve(ctx context.Context, opts ...ServeOption) error {
+	c := serveConfig{
+		addr:              f.addr,
+		readHeaderTimeout: 10 * time.Second,
+		idleTimeout:       2 * time.Minute,
+		shutdownTimeout:   10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	ln := c.listener
+	if ln == nil {
+		var err error
+		if ln, err = net.Listen("tcp", c.addr); err != nil {
+			return err
+		}
+	}
+
+	// Request contexts derive from streams, which is cancelled when shutdown starts
+	// so long-lived event streams end instead of holding Shutdown open.
+	streams, stop := context.WithCancel(context.Background())
+	defer stop()
+	srv := &http.Server{
+		Handler:           f.Router(),
+		ReadTimeout:       c.readTimeout,
+		ReadHeaderTimeout: c.readHeaderTimeout,
+		WriteTimeout:      c.writeTimeout,
+		IdleTimeout:       c.idleTimeout,
+		BaseContext:       func(net.Listener) context.Context { return streams },
+	}
+	srv.RegisterOnShutdown(stop)
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), c.shutdownTimeout)
+	defer cancel()
+	err := srv.Shutdown(shutdown)
+	if serveErr := <-errc; !errors.Is(serveErr, http.ErrServerClosed) {
+		err = errors.Join(err, serveErr)
+	}
+	return err
+}
@@ -0,0 +1,315 @@
+package zero
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+//go:embed runtime/table.js
+var tableJS string
+
+// Align is a column's text alignment.
+type Align string
+
+const (
+	AlignLeft   Align = "left"
+	AlignCenter Align = "center"
+	AlignRight  Align = "right"
+)
+
+func (a Align) valid() bool {
+	return a == "" || a == AlignLeft || a == AlignCenter || a == AlignRight
+}
+
+// Column is a table header and the alignment of its cells.
+type Column struct {
+	Header string
+	Align  Align
+}
+
+// Cell is a table cell. Content renders verbatim; RowSpan above 1 covers the
+// cells below it in the same column, which are then not rendered.
+type Cell struct {
+	Content Renderable
+	RowSpan int
+}
+
+// Table builds a <table> with an optional caption, header row and row headers,
+// and optional client-side sorting and filtering. It is Renderable.
+type Table struct {
+	caption    string
+	columns    []Column
+	rows       [][]Cell
+	rowHeaders bool
+	sortable   bool
+	filterable bool
+	attrs      []Attribute
+}
+
+// NewTable creates a table; header may be nil for a table without a header row.
+func NewTable(header []string, rows [][]string) *Table {
+	t := &Table{}
+	for _, h := range header {
+		t.columns = append(t.columns, Column{Header: h})
+	}
+	for _, row := range rows {
+		cells := make([]any, len(row))
+		for i, v := range row {
+			cells[i] = v
+		}
+		t.AddRow(cells...)
+	}
+	return t
+}
+
+// TableFromStructs builds a table from a slice of structs or struct pointers, one
+// row per element and one column per exported field. A `table` tag renames the
+// column and may add an alignment, one of left, center or right:
+// `table:"Price,right"`; `table:"-"` skips the field.
+func TableFromStructs(items any) (*Table, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("table: %T is not a slice", items)
+	}
+	elem := v.Type().Elem()
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("table: %T is not a slice of structs", items)
+	}
+
+	t := &Table{}
+	var fields [][]int
+	for _, f := range reflect.VisibleFields(elem) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		tag := f.Tag.Get("table")
+		if tag == "-" {
+			continue
+		}
+		header, align, _ := strings.Cut(tag, ",")
+		if header == "" {
+			header = f.Name
+		}
+		if !Align(align).valid() {
+			return nil, fmt.Errorf("table: field %s has unknown alignment %q", f.Name, align)
+		}
+		t.columns = append(t.columns, Column{Header: header, Align: Align(align)})
+		fields = append(fields, f.Index)
+	}
+
+	for i := range v.Len() {
+		item := v.Index(i)
+		if item.Kind() == reflect.Pointer {
+			if item.IsNil() {
+				continue
+			}
+			item = item.Elem()
+		}
+		row := make([]any, len(fields))
+		for j, index := range fields {
+			if f, err := item.FieldByIndexErr(index); err == nil {
+				row[j] = f.Interface()
+			}
+		}
+		t.AddRow(row...)
+	}
+	return t, nil
+}
+
+// TableFromMaps builds a table from rows keyed by column. Columns default to
+// every key in sorted order.
+func TableFromMaps(rows []map[string]any, columns ...string) *Table {
+	if len(columns) == 0 {
+		keys := make(map[string]bool)
+		for _, row := range rows {
+			for k := range row {
+				keys[k] = true
+			}
+		}
+		columns = slices.Sorted(maps.Keys(keys))
+	}
+	t := NewTable(columns, nil)
+	for _, row := range rows {
+		cells := make([]any, len(columns))
+		for i, c := range columns {
+			cells[i] = row[c]
+		}
+		t.AddRow(cells...)
+	}
+	return t
+}
+
+// TableFromCSV reads a table whose first record is the header.
+func TableFromCSV(r io.Reader) (*Table, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("table: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("table: empty csv")
+	}
+	return NewTable(records[0], records[1:]), nil
+}
+
+// TableFromCSVFile reads a CSV file with TableFromCSV.
+func TableFromCSVFile(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return TableFromCSV(f)
+}
+
+// AddRow appends a row. Cells and Renderables are used as-is, nil is empty and
+// anything else is formatted with %v and escaped.
+func (t *Table) AddRow(values ...any) *Table {
+	row := make([]Cell, len(values))
+	for i, v := range values {
+		row[i] = toCell(v)
+	}
+	t.rows = append(t.rows, row)
+	return t
+}
+
+func toCell(v any) Cell {
+	if c, ok := v.(Cell); ok {
+		return c
+	}
+	return Cell{Content: renderable(v)}
+}
+
+func (t *Table) Caption(caption string) *Table {
+	t.caption = caption
+	return t
+}
+
+// Align sets the alignment of column col, adding columns as needed. Values
+// other than the Align constants are ignored.
+func (t *Table) Align(col int, align Align) *Table {
+	for len(t.columns) <= col {
+		t.columns = append(t.columns, Column{})
+	}
+	t.columns[col].Align = align
+	return t
+}
+
+// RowSpan makes the cell at row, col span n rows.
+func (t *Table) RowSpan(row, col, n int) *Table {
+	if row < len(t.rows) && col < len(t.rows[row]) {
+		t.rows[row][col].RowSpan = n
+	}
+	return t
+}
+
+// RowHeaders renders each row's first cell as <th scope="row">.
+func (t *Table) RowHeaders() *Table {
+	t.rowHeaders = true
+	return t
+}
+
+// Sortable sorts rows by a column when its header is clicked. Tables with row
+// spans are left unsorted, as sorting would move spanned cells off their rows.
+func (t *Table) Sortable() *Table {
+	t.sortable = true
+	return t
+}
+
+// Filterable adds a box that hides rows not containing its text.
+func (t *Table) Filterable() *Table {
+	t.filterable = true
+	return t
+}
+
+// With applies attributes to the <table>.
+func (t *Table) With(attrs ...Attribute) *Table {
+	t.attrs = append(t.attrs, attrs...)
+	return t
+}
+
+// Node builds the <table>, followed by the sort and filter script when enabled.
+func (t *Table) Node() *Node {
+	table := El("table").With(t.attrs...)
+	if t.caption != "" {
+		table.Append(Tag("caption", t.caption))
+	}
+	if hasHeaders(t.columns) {
+		tr := El("tr")
+		for _, c := range t.columns {
+			tr.Append(align(Tag("th", c.Header).Attr("scope", "col"), c.Align))
+		}
+		table.Append(El("thead", tr))
+	}
+
+	body := El("tbody")
+	covered := make(map[int]int)
+	for _, row := range t.rows {
+		tr := El("tr")
+		for col, cell := range row {
+			if covered[col] > 0 {
+				covered[col]--
+				continue
+			}
+			td := El("td", cell.Content)
+			if col == 0 && t.rowHeaders {
+				td = El("th", cell.Content).Attr("scope", "row")
+			}
+			if cell.RowSpan > 1 {
+				td.Attr("rowspan", fmt.Sprint(cell.RowSpan))
+				covered[col] = cell.RowSpan - 1
+			}
+			if col < len(t.columns) {
+				align(td, t.columns[col].Align)
+			}
+			tr.Append(td)
+		}
+		body.Append(tr)
+	}
+	table.Append(body)
+
+	sortable := t.sortable && !t.spans()
+	if !sortable && !t.filterable {
+		return table
+	}
+	table.Bool("data-sortable", sortable).Bool("data-filterable", t.filterable)
+	script := One(template.HTML("<script>" + tableJS + "</script>"))
+	return El("div", table, &script).Class("table")
+}
+
+func (t *Table) Render() One {
+	return t.Node().Render()
+}
+
+func (t *Table) spans() bool {
+	for _, row := range t.rows {
+		if slices.ContainsFunc(row, func(c Cell) bool { return c.RowSpan > 1 }) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasHeaders(columns []Column) bool {
+	return slices.ContainsFunc(columns, func(c Column) bool { return c.Header != "" })
+}
+
+func align(n *Node, a Align) *Node {
+	if a == "" || a == AlignLeft || !a.valid() {
+		return n
+	}
+	return n.Style("text-align", string(a))
+}
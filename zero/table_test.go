@@ -0,0 +1,73 @@
+package zero
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	type item struct {
+		Name   string
+		Price  float64 `table:"Cost,right"`
+		secret string
+		Skip   bool `table:"-"`
+		Note   *Node
+	}
+	structs, err := TableFromStructs([]*item{{Name: "a<b", Price: 1.5, Note: Tag("em", "x")}, nil, {Name: "c", Price: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	csvTable, err := TableFromCSV(strings.NewReader("k,v\nx,1\ny,2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name  string
+		table *Table
+		want  string
+	}{
+		{"structs", structs.Caption("Items"),
+			`<table><caption>Items</caption><thead><tr><th scope="col">Name</th><th scope="col" style="text-align: right">Cost</th><th scope="col">Note</th></tr></thead>` +
+				`<tbody><tr><td>a&lt;b</td><td style="text-align: right">1.5</td><td><em>x</em></td></tr>` +
+				`<tr><td>c</td><td style="text-align: right">2</td><td></td></tr></tbody></table>`},
+		{"csv row headers", csvTable.RowHeaders(),
+			`<table><thead><tr><th scope="col">k</th><th scope="col">v</th></tr></thead><tbody><tr><th scope="row">x</th><td>1</td></tr><tr><th scope="row">y</th><td>2</td></tr></tbody></table>`},
+		{"maps", TableFromMaps([]map[string]any{{"b": 1, "a": "x"}, {"a": "y"}}),
+			`<table><thead><tr><th scope="col">a</th><th scope="col">b</th></tr></thead><tbody><tr><td>x</td><td>1</td></tr><tr><td>y</td><td></td></tr></tbody></table>`},
+		{"rowspan", NewTable(nil, [][]string{{"a", "1"}, {"", "2"}, {"b", "3"}}).RowSpan(0, 0, 2),
+			`<table><tbody><tr><td rowspan="2">a</td><td>1</td></tr><tr><td>2</td></tr><tr><td>b</td><td>3</td></tr></tbody></table>`},
+	}
+	for _, c := range cases {
+		if got := string(c.table.Render()); got != c.want {
+			t.Errorf("%s:\n got %s\nwant %s", c.name, got, c.want)
+		}
+	}
+
+	sorted := string(NewTable([]string{"n"}, nil).Sortable().Render())
+	if !strings.HasPrefix(sorted, `<div class="table"><table data-sortable>`) || !strings.Contains(sorted, "<script>") {
+		t.Errorf("sortable table: %s", sorted)
+	}
+	spanned := string(NewTable([]string{"n", "v"}, [][]string{{"a", "1"}, {"", "2"}}).RowSpan(0, 0, 2).Sortable().Render())
+	if strings.Contains(spanned, "data-sortable") {
+		t.Errorf("table with row spans is sortable: %s", spanned)
+	}
+	if got := string(NewTable([]string{"n"}, nil).Align(0, `right; background: red`).Render()); strings.Contains(got, "style") {
+		t.Errorf("unknown alignment rendered: %s", got)
+	}
+	if _, err := TableFromStructs([]int{1}); err == nil {
+		t.Error("TableFromStructs accepted a slice of ints")
+	}
+	type styled struct {
+		Name string `table:"Name,right; background: red"`
+	}
+	if _, err := TableFromStructs([]styled{{"a"}}); err == nil {
+		t.Error("TableFromStructs accepted an unknown alignment")
+	}
+}
+
+func TestElementTableShape(t *testing.T) {
+	got := string(NewElement().Table(2, 1, [][]string{{"a"}, {"b", "c"}}).Render())
+	if want := `<table><tbody><tr><td>a</td><td></td></tr></tbody></table>`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,201 @@
+package zero
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watcher tracks source files and directories and reruns their reload
+// functions when they change on disk. Sources are always recorded; nothing
+// is watched or stamped until Watch is called.
+type watcher struct {
+	logger  *slog.Logger
+	mu      sync.Mutex
+	sources map[string]*source
+	running bool
+	added   chan struct{}
+}
+
+type source struct {
+	reloads []func()
+	stamp   string
+	stamped bool
+}
+
+func newWatcher(logger *slog.Logger) *watcher {
+	return &watcher{
+		logger:  logger,
+		sources: make(map[string]*source),
+		added:   make(chan struct{}, 1),
+	}
+}
+
+// Track registers reload to run whenever the file or directory at path changes in watch mode.
+func (f *fx) Track(path string, reload func()) {
+	f.watch.track(path, reload)
+}
+
+// Watch reloads tracked sources as they change until ctx is done. It uses filesystem
+// notifications where available and falls back to polling every interval.
+func (f *fx) Watch(ctx context.Context, interval time.Duration) error {
+	return f.watch.run(ctx, interval)
+}
+
+func (w *watcher) track(path string, reload func()) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	w.mu.Lock()
+	s, ok := w.sources[path]
+	if !ok {
+		s = &source{}
+		w.sources[path] = s
+		if w.running {
+			s.stamp, s.stamped = stamp(path), true
+		}
+	}
+	s.reloads = append(s.reloads, reload)
+	w.mu.Unlock()
+
+	select {
+	case w.added <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watcher) run(ctx context.Context, interval time.Duration) error {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return errors.New("already watching")
+	}
+	w.running = true
+	for path, s := range w.sources {
+		if !s.stamped {
+			s.stamp, s.stamped = stamp(path), true
+		}
+	}
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.running = false
+		w.mu.Unlock()
+	}()
+
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	var notify <-chan fsnotify.Event
+	var notifyErrs <-chan error
+	fsw, err := fsnotify.NewWatcher()
+	poll := err != nil
+	if err != nil {
+		w.logger.Warn("file notifications unavailable, polling", "interval", interval, "err", err)
+	} else {
+		defer fsw.Close()
+		notify, notifyErrs = fsw.Events, fsw.Errors
+		poll = w.subscribe(fsw) != nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	// Editors often write a file in several steps; settle before reloading.
+	settle := time.NewTimer(time.Hour)
+	settle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.added:
+			if fsw != nil && w.subscribe(fsw) != nil {
+				poll = true
+			}
+		case <-notify:
+			settle.Reset(50 * time.Millisecond)
+		case err := <-notifyErrs:
+			w.logger.Warn("file notification error, polling", "err", err)
+			poll = true
+		case <-settle.C:
+			w.check()
+			if fsw != nil && w.subscribe(fsw) != nil {
+				poll = true
+			}
+		case <-ticker.C:
+			if poll {
+				w.check()
+			}
+		}
+	}
+}
+
+// subscribe adds notification watches for every tracked source: the parent
+// directory of files, so rename-on-save is seen, and every directory of trees.
+func (w *watcher) subscribe(fsw *fsnotify.Watcher) error {
+	w.mu.Lock()
+	paths := make([]string, 0, len(w.sources))
+	for path := range w.sources {
+		paths = append(paths, path)
+	}
+	w.mu.Unlock()
+
+	var errs []error
+	for _, path := range paths {
+		errs = append(errs, filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if p == path && !d.IsDir() {
+				return fsw.Add(filepath.Dir(p))
+			}
+			if d.IsDir() {
+				return fsw.Add(p)
+			}
+			return nil
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// check compares every source against its last stamp and reloads those that changed.
+func (w *watcher) check() {
+	var reloads []func()
+	w.mu.Lock()
+	for path, s := range w.sources {
+		if next := stamp(path); next != s.stamp {
+			s.stamp = next
+			reloads = append(reloads, s.reloads...)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, reload := range reloads {
+		reload()
+	}
+}
+
+// stamp summarizes the names, sizes and modification times under path.
+func stamp(path string) string {
+	var b []byte
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		b = fmt.Appendf(b, "%s|%d|%d\n", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return string(b)
+}
@@ -0,0 +1,49 @@
+package zero
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "page.md")
+	os.WriteFile(file, []byte("one"), 0o644)
+
+	z := New()
+	w := z.(*zeroImpl).Fx.(*fx).watch
+	reloaded := make(chan struct{}, 1)
+	z.Track(file, func() { reloaded <- struct{}{} })
+	w.mu.Lock()
+	for _, s := range w.sources {
+		if s.stamped {
+			t.Error("source stamped before watching")
+		}
+	}
+	w.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go z.Watch(ctx, 10*time.Millisecond)
+	for deadline := time.Now().Add(time.Second); ; {
+		w.mu.Lock()
+		running := w.running
+		w.mu.Unlock()
+		if running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("watch did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	os.WriteFile(file, []byte("two, longer"), 0o644)
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("edit did not reload")
+	}
+}
@@ -1,23 +1,73 @@
 package zero
 
+import (
+	"encoding/json"
+	"net/http"
+)
+
 type Zero interface {
 	Fx
 	Forge
 	Element
+	Subscribe() (<-chan Event, func())
+	HandleEvents(w http.ResponseWriter, r *http.Request)
+	Export(dir string) error
+	HandleManifest(w http.ResponseWriter, r *http.Request)
+	AddForm(form Form) (string, error)
 }
 
 type zeroImpl struct {
 	Fx
 	Forge
 	Element
+	events *hub
+	forms  *forms
 }
 
 func NewZero(pathlessUrl, apiUrl string) Zero {
+	return New(WithPathlessURL(pathlessUrl), WithAPIURL(apiUrl))
+}
+
+func New(opts ...Option) Zero {
+	c := newConfig(opts...)
+	events := newHub()
+	fx := newFx(c)
+	fx.events = events
+	forge := NewForge().(*forge)
+	forge.events = events
+	forge.encodings = c.encodings
+	element := newElement(c)
+	element.images.asset = fx.image
 	z := &zeroImpl{
-		Fx:      NewFx(pathlessUrl, apiUrl).(*fx),
-		Forge:   NewForge().(*forge),
-		Element: NewElement().(*element),
+		Fx:      fx,
+		Forge:   forge,
+		Element: element,
+		events:  events,
 	}
+	z.forms = newForms(c, forge)
 	z.Router().HandleFunc("/frame", z.HandleFrame).Methods("GET", "OPTIONS")
+	z.Router().HandleFunc("/frame/{name}", z.HandleFrame).Methods("GET", "OPTIONS")
+	z.Router().HandleFunc("/events", z.HandleEvents).Methods("GET")
+	z.Router().HandleFunc("/manifest.json", z.HandleManifest).Methods("GET", "OPTIONS")
+	z.Router().Handle("/form/{name}", z.forms).Methods("GET", "POST", "OPTIONS")
 	return z
 }
+
+// Subscribe returns a channel of frame and asset changes and a function that cancels the subscription.
+func (z *zeroImpl) Subscribe() (<-chan Event, func()) {
+	return z.events.subscribe()
+}
+
+// HandleManifest lists the frame index and every asset route as JSON.
+func (z *zeroImpl) HandleManifest(w http.ResponseWriter, r *http.Request) {
+	data, err := json.Marshal(struct {
+		Frames []FrameInfo `json:"frames"`
+		Routes []Route     `json:"routes"`
+	}{z.Index(), z.Routes()})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encode(data, nil).serve(w, r)
+}